@@ -0,0 +1,28 @@
+package helper
+
+import (
+	"github.com/bytedance/sonic"
+	"github.com/bytedance/sonic/ast"
+)
+
+// ResponseCode return the code field of given JSON encoded response App, or
+// empty if there is none. Only the fields before the code are parsed, so the
+// truncated body still work since the code is the first field of App.
+func ResponseCode(b []byte) string {
+	n, err := sonic.Get(b, "code")
+	if err != nil || n.TypeSafe() != ast.V_STRING {
+		return ""
+	}
+	s, _ := n.String()
+	return s
+}
+
+// ExtractResponse decode given JSON encoded response body as map, or just
+// return {} if it's not a JSON object the same way as ExtractRequest.
+func ExtractResponse(b []byte) map[string]any {
+	var v map[string]any
+	if err := sonic.ConfigFastest.Unmarshal(b, &v); err != nil || v == nil {
+		return map[string]any{}
+	}
+	return v
+}
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCode(t *testing.T) {
+	assert.Equal(t, "A-1", ResponseCode([]byte(`{"code":"A-1","message":"Ok"}`)))
+	assert.Equal(t, "A-1", ResponseCode([]byte(`{"code":"A-1","message":"trunc`)))
+	assert.Equal(t, "A-1", ResponseCode([]byte(`{"message":"Ok","data":{"code":"B"},"code":"A-1"}`)))
+	assert.Equal(t, "", ResponseCode([]byte(`{"message":"Ok"}`)))
+	assert.Equal(t, "", ResponseCode([]byte(`not json`)))
+	assert.Equal(t, "", ResponseCode([]byte(`{"code":1}`)))
+	assert.Equal(t, "", ResponseCode(nil))
+}
+
+func TestExtractResponse(t *testing.T) {
+	assert.Equal(t, map[string]any{"code": "A-1"}, ExtractResponse([]byte(`{"code":"A-1"}`)))
+	assert.Equal(t, map[string]any{}, ExtractResponse([]byte(`{"code":"A-1","message":"trunc`)))
+	assert.Equal(t, map[string]any{}, ExtractResponse([]byte(`[1]`)))
+	assert.Equal(t, map[string]any{}, ExtractResponse(nil))
+}
@@ -0,0 +1,228 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DropPolicy define what AsyncWriter should do when the queue is full.
+type DropPolicy int8
+
+const (
+	// Block wait until there is a room in the queue. This never drop any logs
+	// but may increase the latency of the log caller.
+	Block DropPolicy = iota
+	// DropNewest drop the log line that is being written.
+	DropNewest
+	// DropOldest drop the oldest log line in the queue to make room for the
+	// log line that is being written.
+	DropOldest
+)
+
+// asyncMinFlush the minimum duration given to the wrapped Writer Flush, so
+// it still get the chance to flush its own buffer when the queue took the
+// whole duration.
+const asyncMinFlush = 100 * time.Millisecond
+
+// AsyncOpt an option signature for AsyncWriter.
+type AsyncOpt func(*AsyncWriter)
+
+// WithAsyncSize set the maximum number of log lines that can be queued.
+// Default to 1024, zero or negative size is ignored.
+func WithAsyncSize(size int) AsyncOpt {
+	return func(a *AsyncWriter) {
+		if size > 0 {
+			a.buf = make([][]byte, size)
+		}
+	}
+}
+
+// WithAsyncPolicy set what to do when the queue is full. Default to Block.
+func WithAsyncPolicy(p DropPolicy) AsyncOpt {
+	return func(a *AsyncWriter) {
+		a.policy = p
+	}
+}
+
+// NewAsyncWriter return AsyncWriter that wrap given w, so every log line is
+// queued to a bounded ring buffer and written to w by a background flusher
+// instead. This prevents slow target such as file or network from increasing
+// the latency of the log caller.
+func NewAsyncWriter(w Writer, opts ...AsyncOpt) *AsyncWriter {
+	a := &AsyncWriter{wr: w, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if len(a.buf) == 0 {
+		a.buf = make([][]byte, 1024)
+	}
+	a.cond = sync.NewCond(&a.mu)
+
+	go a.run()
+	return a
+}
+
+// AsyncWriter Writer implementer that asynchronously write logs to the wrapped
+// Writer.
+type AsyncWriter struct {
+	wr     Writer
+	policy DropPolicy
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	buf      [][]byte
+	head     int
+	size     int
+	inflight bool
+	closed   bool
+	done     chan struct{}
+
+	// wmu serialize the writes to the wrapped Writer, so the direct write
+	// after closed never race the one that is still in flight
+	wmu sync.Mutex
+
+	dropped atomic.Uint64
+}
+
+// Write implement io.Writer. Given p is copied and queued, so it's safe to be
+// reused by the caller after Write returns.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	if a.closed {
+		// the flusher already stopped, just write it directly
+		a.mu.Unlock()
+		return a.write(p)
+	}
+
+	for a.size == len(a.buf) {
+		switch a.policy {
+		case DropNewest:
+			a.mu.Unlock()
+			a.dropped.Add(1)
+			return len(p), nil
+		case DropOldest:
+			a.buf[a.head] = nil
+			a.head = (a.head + 1) % len(a.buf)
+			a.size--
+			a.dropped.Add(1)
+		default:
+			a.cond.Wait()
+			if a.closed {
+				a.mu.Unlock()
+				return a.write(p)
+			}
+		}
+	}
+
+	line := make([]byte, len(p))
+	copy(line, p)
+	a.buf[(a.head+a.size)%len(a.buf)] = line
+	a.size++
+	a.cond.Broadcast()
+	a.mu.Unlock()
+
+	return len(p), nil
+}
+
+// run the background flusher that write every queued log line to the wrapped
+// Writer until closed.
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+
+	a.mu.Lock()
+	for {
+		for a.size == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if a.closed {
+			a.mu.Unlock()
+			return
+		}
+		line := a.buf[a.head]
+		a.buf[a.head] = nil
+		a.head = (a.head + 1) % len(a.buf)
+		a.size--
+		a.inflight = true
+		a.cond.Broadcast()
+		a.mu.Unlock()
+
+		a.write(line)
+
+		a.mu.Lock()
+		a.inflight = false
+		a.cond.Broadcast()
+	}
+}
+
+// write given p to the wrapped Writer.
+func (a *AsyncWriter) write(p []byte) (int, error) {
+	a.wmu.Lock()
+	defer a.wmu.Unlock()
+	return a.wr.Writer().Write(p)
+}
+
+// Dropped return the number of dropped log lines, either because the queue is
+// full or the queue is not fully drained when flushing.
+func (a *AsyncWriter) Dropped() uint64 { return a.dropped.Load() }
+
+// Len return the number of log lines that are still queued.
+func (a *AsyncWriter) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.size
+}
+
+// Unwrap return the wrapped Writer.
+func (a *AsyncWriter) Unwrap() Writer { return a.wr }
+
+func (a *AsyncWriter) Writer() io.Writer      { return a }
+func (a *AsyncWriter) Output() Output         { return a.wr.Output() }
+func (a *AsyncWriter) Level() Level           { return a.wr.Level() }
+func (a *AsyncWriter) Wait(dur time.Duration) { a.wr.Wait(dur) }
+
+// SetLevel change the wrapped Writer Level if it implements LevelSetter.
+func (a *AsyncWriter) SetLevel(lvl Level) {
+	if ls, ok := a.wr.(LevelSetter); ok {
+		ls.SetLevel(lvl)
+	}
+}
+
+// Flush drain the queue within given dur, stop the background flusher then
+// flush the wrapped Writer with the remaining dur, or a short minimum if
+// there is none left. Any log lines that are still queued after given dur are
+// dropped, and Flush never wait for a log line that is still being written
+// past given dur.
+func (a *AsyncWriter) Flush(dur time.Duration) {
+	deadline := time.Now().Add(dur)
+	timer := time.AfterFunc(dur, func() {
+		a.mu.Lock()
+		a.cond.Broadcast()
+		a.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	a.mu.Lock()
+	for (a.size > 0 || a.inflight) && time.Now().Before(deadline) {
+		a.cond.Wait()
+	}
+	// drop the leftover
+	a.dropped.Add(uint64(a.size))
+	for a.size > 0 {
+		a.buf[a.head] = nil
+		a.head = (a.head + 1) % len(a.buf)
+		a.size--
+	}
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+
+	wait := time.NewTimer(time.Until(deadline))
+	defer wait.Stop()
+	select {
+	case <-a.done:
+	case <-wait.C:
+	}
+	a.wr.Flush(max(time.Until(deadline), asyncMinFlush))
+}
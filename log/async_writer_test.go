@@ -0,0 +1,199 @@
+package log
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter Writer implementer that hold every Write until released.
+type slowWriter struct {
+	*ObservedLog
+	release chan struct{}
+	// active the number of Write that are in progress, overlap is set when
+	// there is more than one at a time
+	active  atomic.Int32
+	overlap atomic.Bool
+	flushes atomic.Int32
+}
+
+func (s *slowWriter) Writer() io.Writer { return s }
+
+func (s *slowWriter) Write(p []byte) (int, error) {
+	if s.active.Add(1) > 1 {
+		s.overlap.Store(true)
+	}
+	defer s.active.Add(-1)
+	<-s.release
+	return s.ObservedLog.Write(p)
+}
+
+func (s *slowWriter) Flush(dur time.Duration) {
+	s.flushes.Add(1)
+	s.ObservedLog.Flush(dur)
+}
+
+func newSlowWriter() *slowWriter {
+	_, obs := NewObserverWriter(DebugLevel, FILE)
+	return &slowWriter{ObservedLog: obs, release: make(chan struct{})}
+}
+
+func line(i int) []byte {
+	return []byte(`{"level":"INFO","msg":"` + strconv.Itoa(i) + `"}`)
+}
+
+func TestNewAsyncWriter(t *testing.T) {
+	t.Run("Should return the expected value in each Writer implementation", func(t *testing.T) {
+		w, _ := NewObserverWriter(WarnLevel, NEWRELIC)
+		aw := NewAsyncWriter(w)
+		assert.Equal(t, aw, aw.Writer())
+		assert.Equal(t, NEWRELIC, aw.Output())
+		assert.Equal(t, WarnLevel, aw.Level())
+		assert.Len(t, aw.buf, 1024)
+
+		aw.SetLevel(DebugLevel)
+		assert.Equal(t, DebugLevel, w.Level())
+
+		aw.Wait(-1)
+		aw.Flush(time.Second)
+	})
+	t.Run("Should use the default size when given size is zero or negative", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			w, _ := NewObserverWriter(WarnLevel, FILE)
+			aw := NewAsyncWriter(w, WithAsyncSize(size))
+			assert.Len(t, aw.buf, 1024)
+			aw.Flush(time.Second)
+		}
+	})
+}
+
+func TestAsyncWriter_Write(t *testing.T) {
+	t.Run("Should write every line to the wrapped Writer and drain on flush", func(t *testing.T) {
+		w, obs := NewObserverWriter(DebugLevel, FILE)
+		aw := NewAsyncWriter(w, WithAsyncSize(4))
+
+		for i := 0; i < 100; i++ {
+			aw.Write(line(i))
+		}
+		aw.Flush(time.Second)
+
+		require.Equal(t, 100, obs.Len())
+		assert.True(t, obs.All()[99].EqualMsg("99"))
+		assert.Equal(t, uint64(0), aw.Dropped())
+
+		// write after flush directly to the wrapped Writer
+		aw.Write(line(100))
+		assert.Equal(t, 101, obs.Len())
+	})
+	t.Run("Should drop the newest line when the queue is full", func(t *testing.T) {
+		sw := newSlowWriter()
+		aw := NewAsyncWriter(sw, WithAsyncSize(2), WithAsyncPolicy(DropNewest))
+
+		aw.Write(line(0))
+		require.Eventually(t, func() bool { return aw.Len() == 0 }, time.Second, time.Millisecond)
+		// line 0 is being written, line 1 & 2 queued and the rest dropped
+		for i := 1; i < 5; i++ {
+			aw.Write(line(i))
+		}
+		assert.Equal(t, uint64(2), aw.Dropped())
+
+		close(sw.release)
+		aw.Flush(time.Second)
+		msgs := make([]string, 0)
+		for _, l := range sw.All() {
+			msgs = append(msgs, l.msg)
+		}
+		assert.Equal(t, []string{"0", "1", "2"}, msgs)
+	})
+	t.Run("Should drop the oldest line when the queue is full", func(t *testing.T) {
+		sw := newSlowWriter()
+		aw := NewAsyncWriter(sw, WithAsyncSize(2), WithAsyncPolicy(DropOldest))
+
+		aw.Write(line(0))
+		require.Eventually(t, func() bool { return aw.Len() == 0 }, time.Second, time.Millisecond)
+		for i := 1; i < 5; i++ {
+			aw.Write(line(i))
+		}
+		assert.Equal(t, uint64(2), aw.Dropped())
+
+		close(sw.release)
+		aw.Flush(time.Second)
+		msgs := make([]string, 0)
+		for _, l := range sw.All() {
+			msgs = append(msgs, l.msg)
+		}
+		assert.Equal(t, []string{"0", "3", "4"}, msgs)
+	})
+	t.Run("Should block when the queue is full", func(t *testing.T) {
+		sw := newSlowWriter()
+		aw := NewAsyncWriter(sw, WithAsyncSize(1))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				aw.Write(line(i))
+			}
+		}()
+		close(sw.release)
+		wg.Wait()
+		aw.Flush(time.Second)
+
+		assert.Equal(t, 5, sw.Len())
+		assert.Equal(t, uint64(0), aw.Dropped())
+	})
+	t.Run("Should drop the leftover when the queue is not drained before the deadline", func(t *testing.T) {
+		sw := newSlowWriter()
+		aw := NewAsyncWriter(sw, WithAsyncSize(8))
+		for i := 0; i < 5; i++ {
+			aw.Write(line(i))
+		}
+		require.Eventually(t, func() bool { return aw.Len() == 4 }, time.Second, time.Millisecond)
+
+		// line 0 is still being written, flush should not wait for it
+		start := time.Now()
+		aw.Flush(10 * time.Millisecond)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, uint64(4), aw.Dropped())
+		assert.Equal(t, 0, sw.Len())
+		assert.Equal(t, int32(1), sw.flushes.Load(), "should still flush the wrapped Writer")
+
+		// the direct write after closed should wait for line 0
+		go aw.Write(line(5))
+		time.Sleep(10 * time.Millisecond)
+		close(sw.release)
+		require.Eventually(t, func() bool { return sw.Len() == 2 }, time.Second, time.Millisecond)
+		assert.False(t, sw.overlap.Load())
+	})
+}
+
+func TestAsyncWriterWithLogger(t *testing.T) {
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			w, obs := NewObserverWriter(DebugLevel, FILE)
+			wr := fn(NewAsyncWriter(w))
+			wr.Init(time.Microsecond)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					wr.Inf("async log", Num("i", i))
+				}(i)
+			}
+			wg.Wait()
+			wr.Flush(time.Second)
+
+			require.Equal(t, 10, obs.Len())
+			assert.True(t, obs.All()[0].EqualMsg("async log"))
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// errBatchBufferFull reported to the drop callback when the oldest log
+	// lines are dropped because the buffer is full.
+	errBatchBufferFull = errors.New("batch: buffer is full")
+	// errBatchClosed reported to the drop callback when writing after the
+	// Writer is flushed.
+	errBatchClosed = errors.New("batch: writer is already flushed")
+	// errBatchUnavailable reported to the drop callback when the leftover
+	// lines could not be shipped while flushing.
+	errBatchUnavailable = errors.New("batch: endpoint is unavailable while flushing")
+	// errSpillFull returned when the spill file already reach its maximum
+	// size.
+	errSpillFull = errors.New("batch: spill file is full")
+)
+
+// permanentError wrap error that should not be retried, such as the client
+// error response.
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// partialError returned when only some of the lines in the batch failed, so
+// only those lines are retried.
+type partialError struct {
+	lines [][]byte
+	err   error
+}
+
+func (p *partialError) Error() string { return p.err.Error() }
+func (p *partialError) Unwrap() error { return p.err }
+
+// batchSender send given lines as a single batch.
+type batchSender func(ctx context.Context, lines [][]byte) error
+
+// newBatcher return batcher that use given send to ship the batch and name as
+// the spill file name. Given optional entry recover the log line from the
+// queued line, so the dropped lines are reported as written by the Logger.
+func newBatcher(cnf BatchConfig, name string, send batchSender, entry func(line []byte) []byte) *batcher {
+	// set default value
+	if cnf.Size == 0 {
+		cnf.Size = 500
+	}
+	if cnf.Interval == 0 {
+		cnf.Interval = 5 * time.Second
+	}
+	if cnf.Buffer == 0 {
+		cnf.Buffer = 10000
+	}
+	if cnf.MaxRetry == 0 {
+		cnf.MaxRetry = 5
+	}
+	if cnf.MinBackoff <= 0 {
+		cnf.MinBackoff = 500 * time.Millisecond
+	}
+	if cnf.MaxBackoff <= 0 {
+		cnf.MaxBackoff = 30 * time.Second
+	}
+	if cnf.SpillSize == 0 {
+		cnf.SpillSize = 100 << 20
+	}
+
+	b := &batcher{
+		cnf:   cnf,
+		send:  send,
+		entry: entry,
+		kick:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	if cnf.SpillDir != "" {
+		b.spill = &spillFile{path: filepath.Join(cnf.SpillDir, name+".spill"), max: cnf.SpillSize}
+		b.spilled = b.spill.exists()
+	}
+
+	go b.run()
+	return b
+}
+
+// batcher buffer log lines and ship them in batch by size or by interval,
+// retry the failed batch with exponential backoff and jitter, then spill it
+// to disk if it's still failing. This is shared by every Writer that ship
+// logs in batch.
+type batcher struct {
+	cnf   BatchConfig
+	send  batchSender
+	entry func(line []byte) []byte
+
+	mu     sync.Mutex
+	buf    [][]byte
+	closed bool
+	// hooks the internal drop callbacks by their owner.
+	hooks map[any]func(lines [][]byte, err error)
+	// failedAt and failErr the last batch that failed to be shipped.
+	failedAt time.Time
+	failErr  error
+
+	// spill and spilled only accessed by the shipper goroutine, or by flush
+	// after the shipper is stopped.
+	spill   *spillFile
+	spilled bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	kick   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// add queue a copy of given line, so it's safe to be reused by the caller.
+func (b *batcher) add(line []byte) {
+	line = bytes.Clone(bytes.TrimSpace(line))
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		b.drop([][]byte{line}, errBatchClosed)
+		return
+	}
+	var dropped [][]byte
+	if len(b.buf) >= b.cnf.Buffer {
+		// drop the oldest to make room
+		dropped = [][]byte{b.buf[0]}
+		b.buf = b.buf[1:]
+	}
+	b.buf = append(b.buf, line)
+	full := len(b.buf) >= b.cnf.Size
+	b.mu.Unlock()
+
+	if dropped != nil {
+		b.drop(dropped, errBatchBufferFull)
+	}
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take return at most Size of the oldest queued lines.
+func (b *batcher) take() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := min(len(b.buf), b.cnf.Size)
+	if n == 0 {
+		return nil
+	}
+	lines := b.buf[:n:n]
+	b.buf = b.buf[n:]
+	return lines
+}
+
+// run the background shipper until stopped.
+func (b *batcher) run() {
+	defer close(b.done)
+	t := time.NewTicker(b.cnf.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+		case <-b.kick:
+		}
+		b.ship(b.ctx)
+	}
+}
+
+// ship send every queued lines in batch until there is nothing left or one of
+// the batch failed.
+func (b *batcher) ship(ctx context.Context) bool {
+	for {
+		lines := b.take()
+		if len(lines) == 0 {
+			return true
+		}
+		if lines, err := b.retry(ctx, lines); err != nil {
+			b.fail(lines, err)
+			return false
+		}
+		// the endpoint is up, good time to replay the spilled lines
+		b.replay(ctx)
+	}
+}
+
+// retry send given lines until succeed, MaxRetry is reached, given ctx is
+// done or the error is permanent. Return the lines that are still failing
+// along with the last error.
+func (b *batcher) retry(ctx context.Context, lines [][]byte) ([][]byte, error) {
+	for attempt := 0; ; attempt++ {
+		err := b.send(ctx, lines)
+		if err == nil {
+			return nil, nil
+		}
+		// only retry the failed lines
+		var pt *partialError
+		if errors.As(err, &pt) {
+			lines = pt.lines
+		}
+		var pe *permanentError
+		if errors.As(err, &pe) || b.cnf.MaxRetry < 0 || attempt >= b.cnf.MaxRetry {
+			return lines, err
+		}
+
+		t := time.NewTimer(b.backoff(attempt))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return lines, err
+		}
+	}
+}
+
+// backoff return the exponential backoff duration of given attempt with
+// jitter between half and the full duration.
+func (b *batcher) backoff(attempt int) time.Duration {
+	d := b.cnf.MaxBackoff
+	// compare before shifting, so it never overflows
+	if attempt < 63 && b.cnf.MinBackoff <= b.cnf.MaxBackoff>>attempt {
+		d = b.cnf.MinBackoff << attempt
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// fail spill given lines to disk if possible, otherwise drop it.
+func (b *batcher) fail(lines [][]byte, err error) {
+	b.mu.Lock()
+	b.failedAt, b.failErr = time.Now(), err
+	b.mu.Unlock()
+
+	var pe *permanentError
+	if b.spill == nil || errors.As(err, &pe) {
+		b.drop(lines, err)
+		return
+	}
+	if serr := b.spill.append(lines); serr != nil {
+		b.drop(lines, errors.Join(err, serr))
+		return
+	}
+	b.spilled = true
+}
+
+// replay send the spilled lines in batch, keep the rest in the spill file if
+// one of them failed.
+func (b *batcher) replay(ctx context.Context) {
+	if !b.spilled {
+		return
+	}
+	lines, err := b.spill.load()
+	if err != nil {
+		return
+	}
+	for len(lines) > 0 {
+		n := min(len(lines), b.cnf.Size)
+		failed, err := b.retry(ctx, lines[:n])
+		if err != nil {
+			var pe *permanentError
+			if errors.As(err, &pe) {
+				// no point to keep it
+				b.drop(failed, err)
+				lines = lines[n:]
+				continue
+			}
+			b.spill.store(append(failed, lines[n:]...))
+			return
+		}
+		lines = lines[n:]
+	}
+	b.spill.store(nil)
+	b.spilled = false
+}
+
+// lastError return when the last batch failed to be shipped and its error.
+func (b *batcher) lastError() (time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failedAt, b.failErr
+}
+
+// addDropHook register given fn that is called with the dropped log lines,
+// replacing the one previously registered by given key.
+func (b *batcher) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.hooks == nil {
+		b.hooks = make(map[any]func(lines [][]byte, err error))
+	}
+	b.hooks[key] = fn
+}
+
+// drop report given lines as the log lines to the drop callback and hooks if
+// any.
+func (b *batcher) drop(lines [][]byte, err error) {
+	b.mu.Lock()
+	hooks := make([]func(lines [][]byte, err error), 0, len(b.hooks)+1)
+	for _, fn := range b.hooks {
+		hooks = append(hooks, fn)
+	}
+	b.mu.Unlock()
+	if b.cnf.OnDrop != nil {
+		hooks = append(hooks, b.cnf.OnDrop)
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	if b.entry != nil {
+		entries := make([][]byte, len(lines))
+		for i, l := range lines {
+			entries[i] = b.entry(l)
+		}
+		lines = entries
+	}
+	for _, fn := range hooks {
+		fn(lines, err)
+	}
+}
+
+// stampedEntry return the log line of given line that is queued along its
+// time as '<unix nano> <log line>'.
+func stampedEntry(line []byte) []byte {
+	_, entry, _ := bytes.Cut(line, []byte{' '})
+	return entry
+}
+
+// flush stop the background shipper then ship every queued lines within given
+// dur. The leftover lines are spilled to disk if possible, otherwise dropped.
+func (b *batcher) flush(dur time.Duration) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	timer := time.AfterFunc(dur, b.cancel)
+	defer timer.Stop()
+	defer b.cancel()
+
+	close(b.stop)
+	<-b.done
+	if b.ship(b.ctx) {
+		return
+	}
+	// the endpoint is down, spill or drop the leftover
+	b.mu.Lock()
+	lines := b.buf
+	b.buf = nil
+	b.mu.Unlock()
+	if len(lines) > 0 {
+		err := context.Cause(b.ctx)
+		if err == nil {
+			err = errBatchUnavailable
+		}
+		b.fail(lines, err)
+	}
+}
+
+// spillFile append only file that hold the failed lines, one per line.
+type spillFile struct {
+	path string
+	max  int64
+}
+
+// exists return true if there is any spilled lines.
+func (s *spillFile) exists() bool {
+	fi, err := os.Stat(s.path)
+	return err == nil && fi.Size() > 0
+}
+
+// append given lines to the spill file. Return errSpillFull if it would
+// exceed the maximum size.
+func (s *spillFile) append(lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.Write(l)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size()+int64(buf.Len()) > s.max {
+		return errSpillFull
+	}
+	_, err = f.Write(buf.Bytes())
+	return err
+}
+
+// load return all the spilled lines.
+func (s *spillFile) load() ([][]byte, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]byte
+	for _, l := range bytes.Split(b, []byte{'\n'}) {
+		if len(l) > 0 {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
+
+// store replace the spill file content with given lines, or remove it if
+// there is nothing left.
+func (s *spillFile) store(lines [][]byte) error {
+	if len(lines) == 0 {
+		return os.Remove(s.path)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(bytes.Join(lines, []byte{'\n'}), '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
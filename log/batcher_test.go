@@ -0,0 +1,209 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender batchSender that record every sent batch and fail based on the
+// err func.
+type fakeSender struct {
+	mu      sync.Mutex
+	batches [][]string
+	calls   int
+	err     func(call int) error
+}
+
+func (f *fakeSender) send(_ context.Context, lines [][]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		if err := f.err(f.calls); err != nil {
+			return err
+		}
+	}
+	var batch []string
+	for _, l := range lines {
+		batch = append(batch, string(l))
+	}
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func (f *fakeSender) sent() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.batches
+}
+
+func TestBatcher(t *testing.T) {
+	t.Run("Should ship by size", func(t *testing.T) {
+		fs := &fakeSender{}
+		bt := newBatcher(BatchConfig{Size: 2, Interval: time.Hour}, "test", fs.send, nil)
+		bt.add([]byte("1\n"))
+		bt.add([]byte("2\n"))
+		assert.Eventually(t, func() bool { return len(fs.sent()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"1", "2"}, fs.sent()[0])
+		bt.flush(time.Second)
+	})
+	t.Run("Should ship by interval", func(t *testing.T) {
+		fs := &fakeSender{}
+		bt := newBatcher(BatchConfig{Size: 100, Interval: 10 * time.Millisecond}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		assert.Eventually(t, func() bool { return len(fs.sent()) == 1 }, time.Second, time.Millisecond)
+		bt.flush(time.Second)
+	})
+	t.Run("Should retry with backoff", func(t *testing.T) {
+		fs := &fakeSender{err: func(call int) error {
+			if call < 3 {
+				return errors.New("oops")
+			}
+			return nil
+		}}
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, MinBackoff: time.Millisecond}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		bt.flush(time.Second)
+		assert.Equal(t, 3, fs.calls)
+		assert.Equal(t, [][]string{{"1"}}, fs.sent())
+	})
+	t.Run("Should not retry permanent error", func(t *testing.T) {
+		fs := &fakeSender{err: func(int) error { return &permanentError{errors.New("bad request")} }}
+		var dropped []string
+		drop := func(lines [][]byte, err error) {
+			for _, l := range lines {
+				dropped = append(dropped, string(l))
+			}
+			assert.EqualError(t, err, "bad request")
+		}
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, MinBackoff: time.Millisecond, SpillDir: t.TempDir(), OnDrop: drop}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		bt.flush(time.Second)
+		assert.Equal(t, 1, fs.calls)
+		assert.Equal(t, []string{"1"}, dropped)
+		assert.False(t, bt.spill.exists())
+	})
+	t.Run("Should drop the oldest when the buffer is full", func(t *testing.T) {
+		fs := &fakeSender{}
+		var dropped []string
+		drop := func(lines [][]byte, err error) {
+			dropped = append(dropped, string(lines[0]))
+			assert.ErrorIs(t, err, errBatchBufferFull)
+		}
+		bt := newBatcher(BatchConfig{Size: 10, Buffer: 2, Interval: time.Hour, OnDrop: drop}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		bt.add([]byte("2"))
+		bt.add([]byte("3"))
+		bt.flush(time.Second)
+		assert.Equal(t, []string{"1"}, dropped)
+		assert.Equal(t, [][]string{{"2", "3"}}, fs.sent())
+	})
+	t.Run("Should report the log line to the drop callback and hooks", func(t *testing.T) {
+		fs := &fakeSender{err: func(int) error { return &permanentError{errors.New("bad request")} }}
+		var dropped, hooked []string
+		drop := func(lines [][]byte, _ error) { dropped = append(dropped, string(lines[0])) }
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, OnDrop: drop}, "test", fs.send, stampedEntry)
+		bt.addDropHook("a", func([][]byte, error) { t.Error("should be replaced") })
+		bt.addDropHook("a", func(lines [][]byte, _ error) { hooked = append(hooked, string(lines[0])) })
+		bt.add([]byte(`1695364719000000000 {"msg":"hi there"}`))
+		bt.flush(time.Second)
+		assert.Equal(t, []string{`{"msg":"hi there"}`}, dropped)
+		assert.Equal(t, dropped, hooked)
+	})
+	t.Run("Should spill to disk then replay once the endpoint is up", func(t *testing.T) {
+		dir := t.TempDir()
+		down := &fakeSender{err: func(int) error { return errors.New("connection refused") }}
+		bt := newBatcher(BatchConfig{Size: 2, Interval: time.Hour, MaxRetry: -1, SpillDir: dir}, "test", down.send, nil)
+		bt.add([]byte("1"))
+		bt.add([]byte("2"))
+		bt.add([]byte("3"))
+		bt.flush(time.Second)
+		require.FileExists(t, dir+"/test.spill")
+
+		// simulate restart when the endpoint is up again
+		up := &fakeSender{}
+		bt = newBatcher(BatchConfig{Size: 2, Interval: time.Hour, SpillDir: dir}, "test", up.send, nil)
+		bt.add([]byte("4"))
+		bt.flush(time.Second)
+		assert.Equal(t, [][]string{{"4"}, {"1", "2"}, {"3"}}, up.sent())
+		_, err := os.Stat(dir + "/test.spill")
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("Should not block flush more than the given duration", func(t *testing.T) {
+		fs := &fakeSender{err: func(int) error { return errors.New("oops") }}
+		var dropped int
+		drop := func(lines [][]byte, _ error) { dropped += len(lines) }
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, MinBackoff: time.Hour, OnDrop: drop}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		bt.add([]byte("2"))
+
+		start := time.Now()
+		bt.flush(50 * time.Millisecond)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, 2, dropped)
+
+		// write after flush is dropped
+		bt.add([]byte("3"))
+		assert.Equal(t, 3, dropped)
+	})
+	t.Run("Should always report the drop with an error", func(t *testing.T) {
+		fs := &fakeSender{err: func(int) error { return errors.New("oops") }}
+		var mu sync.Mutex
+		var dropped int
+		drop := func(lines [][]byte, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped += len(lines)
+			assert.Error(t, err)
+		}
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, MaxRetry: -1, OnDrop: drop}, "test", fs.send, nil)
+		bt.add([]byte("1"))
+		bt.add([]byte("2"))
+		bt.flush(time.Second)
+		assert.Equal(t, 2, dropped)
+	})
+	t.Run("Should report the last failed batch", func(t *testing.T) {
+		fs := &fakeSender{err: func(call int) error {
+			if call == 1 {
+				return errors.New("oops")
+			}
+			return nil
+		}}
+		bt := newBatcher(BatchConfig{Size: 1, Interval: time.Hour, MaxRetry: -1}, "test", fs.send, nil)
+		at, err := bt.lastError()
+		assert.True(t, at.IsZero())
+		assert.NoError(t, err)
+
+		bt.add([]byte("1"))
+		bt.flush(time.Second)
+		at, err = bt.lastError()
+		assert.False(t, at.IsZero())
+		assert.EqualError(t, err, "oops")
+	})
+}
+
+func TestBatcher_Backoff(t *testing.T) {
+	bt := &batcher{cnf: BatchConfig{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}}
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		d := bt.backoff(attempt)
+		assert.GreaterOrEqual(t, d, max/2)
+		assert.LessOrEqual(t, d, max)
+	}
+	assert.LessOrEqual(t, bt.backoff(100), time.Second)
+
+	// should not overflow with large backoff
+	bt = &batcher{cnf: BatchConfig{MinBackoff: time.Hour, MaxBackoff: 24 * time.Hour}}
+	for attempt := 0; attempt < 100; attempt++ {
+		d := bt.backoff(attempt)
+		assert.Greater(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 24*time.Hour)
+	}
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// callerOf return the trimmed 'dir/file.go:line' and the fully qualified
+// function name of the caller at given skip, where 0 is the caller of
+// callerOf. Use the same format as zap, so both backend produce the same
+// output.
+func callerOf(skip int) (caller, function string, ok bool) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", "", false
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+	return zapcore.NewEntryCaller(pc, file, line, ok).TrimmedPath(), function, true
+}
+
+// frameOf return the frame of given program counter such as the one recorded
+// by slog.Record.
+func frameOf(pc uintptr) (runtime.Frame, bool) {
+	if pc == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame, frame.File != ""
+}
+
+// stacktrace return the stack trace starting from the caller at given skip,
+// where 0 is the caller of stacktrace, minus the final runtime.main or
+// runtime.goexit frame. Use the same format as zap, so both backend produce
+// the same output.
+func stacktrace(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, len(pcs)*2)
+		n = runtime.Callers(skip+2, pcs)
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for frame, more := frames.Next(); more; frame, more = frames.Next() {
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(frame.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.Line))
+	}
+	return sb.String()
+}
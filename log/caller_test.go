@@ -0,0 +1,90 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logAt log each level from the exact same line regardless of the backend.
+func logAt(wr Logger) { wr.Inf("info log"); wr.Err("error log") }
+
+// wrappedInf wrap Logger, so caller skip is needed to report the real caller.
+func wrappedInf(wr Logger, msg string) {
+	wr.Inf(msg)
+}
+
+func TestCallerOf(t *testing.T) {
+	caller, fn, ok := callerOf(0)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(caller, "log/caller_test.go:"))
+	assert.Equal(t, "github.com/mdanialr/api-pkg-go/log.TestCallerOf", fn)
+}
+
+func TestStacktrace(t *testing.T) {
+	st := stacktrace(0)
+	lines := strings.Split(st, "\n")
+	require.Greater(t, len(lines), 2)
+	assert.Equal(t, "github.com/mdanialr/api-pkg-go/log.TestStacktrace", lines[0])
+	assert.Contains(t, lines[1], "log/caller_test.go:")
+	assert.NotContains(t, st, "runtime.goexit")
+}
+
+func TestLoggerWithCaller(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	results := make(map[string][]loggedLog)
+	for name, fn := range builders {
+		writer, obs := NewObserverWriter(DebugLevel, FILE)
+		wr := fn([]Writer{writer}, WithCaller(), WithStacktrace(ErrorLevel))
+		wr.Init(time.Microsecond)
+		logAt(wr.With(String("hello", "world")))
+		results[name] = obs.TakeAll()
+	}
+
+	for name, logs := range results {
+		t.Run(name, func(t *testing.T) {
+			require.Len(t, logs, 2)
+			inf, err := logs[0], logs[1]
+			assert.Contains(t, inf.Get("caller"), "log/caller_test.go:13")
+			assert.Equal(t, "github.com/mdanialr/api-pkg-go/log.logAt", inf.Get("function"))
+			assert.Nil(t, inf.Get("stacktrace"))
+
+			assert.Equal(t, "log/caller_test.go:13", err.Get("caller"))
+			require.NotNil(t, err.Get("stacktrace"))
+			assert.True(t, strings.HasPrefix(err.Get("stacktrace").(string), "github.com/mdanialr/api-pkg-go/log.logAt\n"))
+		})
+	}
+	t.Run("Both backend should produce the same output", func(t *testing.T) {
+		for i := range results["zap"] {
+			zl, sl := results["zap"][i], results["slog"][i]
+			assert.Equal(t, zl.Get("caller"), sl.Get("caller"))
+			assert.Equal(t, zl.Get("function"), sl.Get("function"))
+			assert.Equal(t, zl.Get("stacktrace"), sl.Get("stacktrace"))
+		}
+	})
+}
+
+func TestLoggerWithCallerSkip(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(DebugLevel, FILE)
+			wr := fn([]Writer{writer}, WithCaller(), WithCallerSkip(1))
+			wr.Init(time.Microsecond)
+
+			wrappedInf(wr, "wrapped")
+			require.Equal(t, 1, obs.Len())
+			l := obs.All()[0]
+			assert.Equal(t, "github.com/mdanialr/api-pkg-go/log.TestLoggerWithCallerSkip.func1", l.Get("function"))
+		})
+	}
+}
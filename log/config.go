@@ -1,5 +1,13 @@
 package log
 
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
 // NewConfig return new Config after applying given options.
 func NewConfig(opts ...ConfigOpt) *Config {
 	var c Config
@@ -13,13 +21,25 @@ func NewConfig(opts ...ConfigOpt) *Config {
 type (
 	// Config required object that holds any necessary data used by each log output implementation
 	Config struct {
-		NR   NRConfig
-		File FileConfig
+		NR       NRConfig
+		File     FileConfig
+		Platform PlatformConfig
+		Syslog   SyslogConfig
+		HTTP     HTTPConfig
+		Loki     LokiConfig
+		Elastic  ElasticConfig
+		Fluent   FluentConfig
+		OTLP     OTLPConfig
+		GELF     GELFConfig
 	}
 	// NRConfig specific config for new relic as the log output
 	NRConfig struct {
 		Name    string
 		License string
+		// App existing new relic application to record the logs to, so Name
+		// and License are ignored. The Writer won't shut it down on Flush
+		// since it's owned by the caller.
+		App *newrelic.Application
 	}
 	// FileConfig specific config for file as the log output
 	FileConfig struct {
@@ -27,6 +47,203 @@ type (
 		Size int
 		Age  int
 		Num  int
+		// Rotate the time based rotation on top of the size based one. The
+		// current file is date-stamped such as 'app-2006-01-02.log' and the
+		// previous ones are also subject to Age and Num. The size based
+		// backups are compressed & cleaned up when the period is over.
+		// Default to FileRotateNone.
+		Rotate FileRotation
+		// Compress the rotated files using gzip.
+		Compress bool
+		// UTC use UTC instead of local time for the rotated file names.
+		UTC bool
+		// Symlink keep a symlink at Path that point to the current
+		// date-stamped file. Only used along with Rotate.
+		Symlink bool
+		// Reopen close the file on SIGHUP so it's reopened on the next write,
+		// which is required when the file is rotated by external logrotate.
+		Reopen bool
+	}
+	// PlatformConfig specific config for container platform stdout as the
+	// log output
+	PlatformConfig struct {
+		Stderr bool
+	}
+	// SyslogConfig specific config for syslog server as the log output
+	SyslogConfig struct {
+		// Network one of 'udp', 'tcp', 'tls', 'unix' or 'unixgram'. Default to
+		// 'udp'.
+		Network string
+		// Addr the syslog server address such as 'localhost:514' or the unix
+		// socket path such as '/dev/log'.
+		Addr string
+		// TLS used when the Network is 'tls'.
+		TLS *tls.Config
+		// AppName the RFC 5424 APP-NAME. Default to the executable name.
+		AppName string
+		// Hostname the RFC 5424 HOSTNAME. Default to os.Hostname.
+		Hostname string
+		// Facility default to FacilityUser when nil, since FacilityKern is
+		// the zero value.
+		Facility *SyslogFacility
+		// SDID the RFC 5424 SD-ID of the structured data that holds the log
+		// level and Data. Default to 'log@32473'.
+		SDID string
+		// Data additional static structured data parameters such as the
+		// environment or region.
+		Data map[string]string
+		// MaxBackoff the maximum delay between reconnection attempts. Default
+		// to 30 seconds.
+		MaxBackoff time.Duration
+	}
+	// HTTPConfig specific config for arbitrary http collector as the log
+	// output
+	HTTPConfig struct {
+		// URL the collector endpoint that the batch is POSTed to.
+		URL string
+		// Headers additional request headers such as the api key.
+		Headers map[string]string
+		// Format the request body format. Default to HTTPNDJSON.
+		Format HTTPFormat
+		// Gzip compress the request body using gzip.
+		Gzip bool
+		// Client used to send the request. Default to http.Client with 10
+		// seconds timeout.
+		Client *http.Client
+		Batch  BatchConfig
+	}
+	// LokiConfig specific config for grafana loki as the log output
+	LokiConfig struct {
+		// URL the loki base url such as 'http://localhost:3100'.
+		URL string
+		// TenantID sent as X-Scope-OrgID header for multi-tenant loki.
+		TenantID string
+		// Username and Password for basic auth.
+		Username string
+		Password string
+		// Labels static stream labels such as the app name.
+		Labels map[string]string
+		// LabelKeys the top-level Log keys that are promoted to stream labels
+		// such as 'app_env' and 'level'. Default to 'level'.
+		LabelKeys []string
+		// Client used to send the request. Default to http.Client with 10
+		// seconds timeout.
+		Client *http.Client
+		Batch  BatchConfig
+	}
+	// ElasticConfig specific config for elasticsearch or opensearch as the
+	// log output
+	ElasticConfig struct {
+		// URL the cluster base url such as 'http://localhost:9200'.
+		URL string
+		// Index the index name, any Go time layout inside curly braces is
+		// replaced with the log time. Default to 'logs-{2006.01.02}'.
+		Index string
+		// LocalTime use local time instead of UTC for the index name.
+		LocalTime bool
+		// APIKey for api key auth, take precedence over basic auth.
+		APIKey string
+		// Username and Password for basic auth.
+		Username string
+		Password string
+		// OnFailure optional callback that is called for every document that
+		// is dropped or rejected by the cluster.
+		OnFailure func(doc []byte, err error)
+		// Client used to send the request. Default to http.Client with 10
+		// seconds timeout.
+		Client *http.Client
+		Batch  BatchConfig
+	}
+	// FluentConfig specific config for fluentd or fluent-bit forward input as
+	// the log output
+	FluentConfig struct {
+		// Network either 'tcp' or 'unix'. Default to 'tcp'.
+		Network string
+		// Addr the server address or the unix socket path. Default to
+		// 'localhost:24224'.
+		Addr string
+		// Tag the fluent tag of every log. Default to 'app'.
+		Tag string
+		// Mode default to FluentForward.
+		Mode FluentMode
+		// Ack wait for the server to acknowledge every batch.
+		Ack bool
+		// Timeout the dial, write and ack timeout. Default to 10 seconds.
+		Timeout time.Duration
+		Batch   BatchConfig
+	}
+	// OTLPConfig specific config for opentelemetry collector as the log
+	// output
+	OTLPConfig struct {
+		// URL the OTLP/HTTP logs endpoint. Default to
+		// 'http://localhost:4318/v1/logs'.
+		URL string
+		// Encoding the request body encoding. Default to OTLPProtobuf.
+		Encoding OTLPEncoding
+		// Headers additional request headers such as the api key.
+		Headers map[string]string
+		// ServiceName set as 'service.name' resource attribute.
+		ServiceName string
+		// Resource additional resource attributes such as
+		// 'deployment.environment'.
+		Resource map[string]string
+		// Gzip compress the request body using gzip.
+		Gzip bool
+		// Client used to send the request. Default to http.Client with 10
+		// seconds timeout.
+		Client *http.Client
+		Batch  BatchConfig
+	}
+	// GELFConfig specific config for graylog GELF input as the log output
+	GELFConfig struct {
+		// Network either 'udp' or 'tcp'. Default to 'udp'.
+		Network string
+		// Addr the graylog GELF input address. Default to 'localhost:12201'.
+		Addr string
+		// Host the GELF host field. Default to os.Hostname.
+		Host string
+		// Compression the UDP message compression. TCP message is never
+		// compressed since graylog does not support it. Default to
+		// GELFNoCompression.
+		Compression GELFCompression
+		// ChunkSize the maximum UDP datagram size, message that exceed it is
+		// sent in chunks. Default to 1420.
+		ChunkSize int
+		// MaxBackoff the maximum delay between reconnection attempts. Default
+		// to 30 seconds.
+		MaxBackoff time.Duration
+	}
+	// BatchConfig specific config for Writer that ship logs in batch
+	BatchConfig struct {
+		// Size the maximum number of log lines in a batch. The batch is sent
+		// as soon as it's full. Default to 500.
+		Size int
+		// Interval how often the batch is sent even if it's not full yet.
+		// Default to 5 seconds.
+		Interval time.Duration
+		// Buffer the maximum number of queued log lines, the oldest are
+		// dropped when it's full. Default to 10000.
+		Buffer int
+		// MaxRetry the maximum number of retry for a failed batch. Default to
+		// 5, set to negative value to disable retry.
+		MaxRetry int
+		// MinBackoff the initial delay between retries that is doubled on each
+		// retry. Default to 500 milliseconds.
+		MinBackoff time.Duration
+		// MaxBackoff the maximum delay between retries. Default to 30 seconds.
+		MaxBackoff time.Duration
+		// SpillDir the directory to keep the batch that is still failing after
+		// all the retries, so it can be resent once the endpoint is up again.
+		// Disabled if empty.
+		SpillDir string
+		// SpillSize the maximum size in bytes of the spill file. Default to
+		// 100 MB.
+		SpillSize int64
+		// OnDrop optional callback that is called with the log lines that
+		// are dropped, either because the buffer is full, the batch is still
+		// failing after all the retries without SpillDir, or it's written
+		// after the Writer is flushed.
+		OnDrop func(lines [][]byte, err error)
 	}
 )
 
@@ -39,8 +256,45 @@ const (
 	CONSOLE  Output = iota // CONSOLE target log output to console/terminal
 	NEWRELIC               // NEWRELIC target log output directly to new relic via their client sdk
 	FILE                   // FILE target log output to local file
+	PLATFORM               // PLATFORM target log output to stdout as single-line JSON for container platform
+	SYSLOG                 // SYSLOG target log output to syslog server using RFC 5424 format
+	HTTP                   // HTTP target log output to arbitrary http collector in batch
+	LOKI                   // LOKI target log output to grafana loki push api in batch
+	ELASTIC                // ELASTIC target log output to elasticsearch or opensearch bulk api in batch
+	FLUENT                 // FLUENT target log output to fluentd or fluent-bit using forward protocol
+	OTLP                   // OTLP target log output to opentelemetry collector over OTLP/HTTP in batch
+	GELF                   // GELF target log output to graylog GELF input over udp or tcp
 )
 
+// String returns the lower-case name of the Output.
+func (o Output) String() string {
+	switch o {
+	case CONSOLE:
+		return "console"
+	case NEWRELIC:
+		return "newrelic"
+	case FILE:
+		return "file"
+	case PLATFORM:
+		return "platform"
+	case SYSLOG:
+		return "syslog"
+	case HTTP:
+		return "http"
+	case LOKI:
+		return "loki"
+	case ELASTIC:
+		return "elastic"
+	case FLUENT:
+		return "fluent"
+	case OTLP:
+		return "otlp"
+	case GELF:
+		return "gelf"
+	}
+	return "unknown"
+}
+
 // WithNRAppName set new relic application name.
 func WithNRAppName(name string) ConfigOpt {
 	return func(c *Config) {
@@ -55,6 +309,14 @@ func WithNRLicense(lic string) ConfigOpt {
 	}
 }
 
+// WithNRApp set existing new relic application to record the logs to instead
+// of creating a new one.
+func WithNRApp(app *newrelic.Application) ConfigOpt {
+	return func(c *Config) {
+		c.NR.App = app
+	}
+}
+
 // WithFilePath set target readable directory + local file which the log data
 // will be written.
 func WithFilePath(p string) ConfigOpt {
@@ -85,3 +347,408 @@ func WithFileMaxBackup(max int) ConfigOpt {
 		c.File.Num = max
 	}
 }
+
+// WithFileRotate set the time based rotation, either hourly or daily.
+func WithFileRotate(r FileRotation) ConfigOpt {
+	return func(c *Config) {
+		c.File.Rotate = r
+	}
+}
+
+// WithFileCompress set whether the rotated files should be compressed using
+// gzip.
+func WithFileCompress(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.File.Compress = b
+	}
+}
+
+// WithFileUTC set whether to use UTC instead of local time for the rotated
+// file names.
+func WithFileUTC(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.File.UTC = b
+	}
+}
+
+// WithFileSymlink set whether to keep a symlink at the file path that point
+// to the current date-stamped file.
+func WithFileSymlink(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.File.Symlink = b
+	}
+}
+
+// WithFileReopen set whether the file should be reopened on SIGHUP.
+func WithFileReopen(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.File.Reopen = b
+	}
+}
+
+// WithPlatformStderr set whether ErrorLevel and above logs should be written
+// to stderr instead of stdout.
+func WithPlatformStderr(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.Platform.Stderr = b
+	}
+}
+
+// WithSyslogAddr set the syslog server network and address. Network is one of
+// 'udp', 'tcp', 'tls', 'unix' or 'unixgram'.
+func WithSyslogAddr(network, addr string) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.Network = network
+		c.Syslog.Addr = addr
+	}
+}
+
+// WithSyslogTLS set the tls config used when the syslog network is 'tls'.
+func WithSyslogTLS(cnf *tls.Config) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.TLS = cnf
+	}
+}
+
+// WithSyslogAppName set the syslog APP-NAME.
+func WithSyslogAppName(name string) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.AppName = name
+	}
+}
+
+// WithSyslogHostname set the syslog HOSTNAME.
+func WithSyslogHostname(host string) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.Hostname = host
+	}
+}
+
+// WithSyslogFacility set the syslog facility.
+func WithSyslogFacility(f SyslogFacility) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.Facility = &f
+	}
+}
+
+// WithSyslogData add static structured data parameter that is sent along
+// every log.
+func WithSyslogData(key, val string) ConfigOpt {
+	return func(c *Config) {
+		if c.Syslog.Data == nil {
+			c.Syslog.Data = make(map[string]string)
+		}
+		c.Syslog.Data[key] = val
+	}
+}
+
+// WithSyslogMaxBackoff set the maximum delay between reconnection attempts.
+func WithSyslogMaxBackoff(dur time.Duration) ConfigOpt {
+	return func(c *Config) {
+		c.Syslog.MaxBackoff = dur
+	}
+}
+
+// WithHTTPURL set the http collector endpoint.
+func WithHTTPURL(url string) ConfigOpt {
+	return func(c *Config) {
+		c.HTTP.URL = url
+	}
+}
+
+// WithHTTPHeader add request header that is sent along every batch.
+func WithHTTPHeader(key, val string) ConfigOpt {
+	return func(c *Config) {
+		if c.HTTP.Headers == nil {
+			c.HTTP.Headers = make(map[string]string)
+		}
+		c.HTTP.Headers[key] = val
+	}
+}
+
+// WithHTTPFormat set the http request body format.
+func WithHTTPFormat(f HTTPFormat) ConfigOpt {
+	return func(c *Config) {
+		c.HTTP.Format = f
+	}
+}
+
+// WithHTTPGzip set whether the http request body should be compressed using
+// gzip.
+func WithHTTPGzip(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.HTTP.Gzip = b
+	}
+}
+
+// WithHTTPClient set the http client used to send the batch.
+func WithHTTPClient(cl *http.Client) ConfigOpt {
+	return func(c *Config) {
+		c.HTTP.Client = cl
+	}
+}
+
+// WithHTTPBatch set how the logs are batched and retried.
+func WithHTTPBatch(b BatchConfig) ConfigOpt {
+	return func(c *Config) {
+		c.HTTP.Batch = b
+	}
+}
+
+// WithLokiURL set the loki base url.
+func WithLokiURL(url string) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.URL = url
+	}
+}
+
+// WithLokiTenant set the loki tenant id.
+func WithLokiTenant(id string) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.TenantID = id
+	}
+}
+
+// WithLokiBasicAuth set the loki basic auth credential.
+func WithLokiBasicAuth(user, pass string) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.Username = user
+		c.Loki.Password = pass
+	}
+}
+
+// WithLokiLabel add static stream label.
+func WithLokiLabel(key, val string) ConfigOpt {
+	return func(c *Config) {
+		if c.Loki.Labels == nil {
+			c.Loki.Labels = make(map[string]string)
+		}
+		c.Loki.Labels[key] = val
+	}
+}
+
+// WithLokiLabelKeys set the Log keys that are promoted to stream labels.
+func WithLokiLabelKeys(keys ...string) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.LabelKeys = keys
+	}
+}
+
+// WithLokiClient set the http client used to push the logs.
+func WithLokiClient(cl *http.Client) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.Client = cl
+	}
+}
+
+// WithLokiBatch set how the logs are batched and retried.
+func WithLokiBatch(b BatchConfig) ConfigOpt {
+	return func(c *Config) {
+		c.Loki.Batch = b
+	}
+}
+
+// WithElasticURL set the elasticsearch or opensearch base url.
+func WithElasticURL(url string) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.URL = url
+	}
+}
+
+// WithElasticIndex set the index name, any Go time layout inside curly braces
+// is replaced with the log time such as 'app-logs-{2006.01.02}'.
+func WithElasticIndex(index string) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.Index = index
+	}
+}
+
+// WithElasticLocalTime set whether to use local time instead of UTC for the
+// index name.
+func WithElasticLocalTime(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.LocalTime = b
+	}
+}
+
+// WithElasticAPIKey set the api key credential.
+func WithElasticAPIKey(key string) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.APIKey = key
+	}
+}
+
+// WithElasticBasicAuth set the basic auth credential.
+func WithElasticBasicAuth(user, pass string) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.Username = user
+		c.Elastic.Password = pass
+	}
+}
+
+// WithElasticOnFailure set the callback for every dropped or rejected
+// document.
+func WithElasticOnFailure(fn func(doc []byte, err error)) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.OnFailure = fn
+	}
+}
+
+// WithElasticClient set the http client used to send the bulk request.
+func WithElasticClient(cl *http.Client) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.Client = cl
+	}
+}
+
+// WithElasticBatch set how the logs are batched and retried.
+func WithElasticBatch(b BatchConfig) ConfigOpt {
+	return func(c *Config) {
+		c.Elastic.Batch = b
+	}
+}
+
+// WithFluentAddr set the fluent server network and address. Network is either
+// 'tcp' or 'unix'.
+func WithFluentAddr(network, addr string) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Network = network
+		c.Fluent.Addr = addr
+	}
+}
+
+// WithFluentTag set the fluent tag.
+func WithFluentTag(tag string) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Tag = tag
+	}
+}
+
+// WithFluentMode set the fluent forward mode.
+func WithFluentMode(m FluentMode) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Mode = m
+	}
+}
+
+// WithFluentAck set whether to wait for the server to acknowledge every
+// batch.
+func WithFluentAck(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Ack = b
+	}
+}
+
+// WithFluentTimeout set the dial, write and ack timeout.
+func WithFluentTimeout(dur time.Duration) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Timeout = dur
+	}
+}
+
+// WithFluentBatch set how the logs are batched and retried.
+func WithFluentBatch(b BatchConfig) ConfigOpt {
+	return func(c *Config) {
+		c.Fluent.Batch = b
+	}
+}
+
+// WithOTLPURL set the OTLP/HTTP logs endpoint.
+func WithOTLPURL(url string) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.URL = url
+	}
+}
+
+// WithOTLPEncoding set the OTLP/HTTP request body encoding.
+func WithOTLPEncoding(enc OTLPEncoding) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.Encoding = enc
+	}
+}
+
+// WithOTLPHeader add request header that is sent along every export.
+func WithOTLPHeader(key, val string) ConfigOpt {
+	return func(c *Config) {
+		if c.OTLP.Headers == nil {
+			c.OTLP.Headers = make(map[string]string)
+		}
+		c.OTLP.Headers[key] = val
+	}
+}
+
+// WithOTLPServiceName set the 'service.name' resource attribute.
+func WithOTLPServiceName(name string) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.ServiceName = name
+	}
+}
+
+// WithOTLPResource add resource attribute.
+func WithOTLPResource(key, val string) ConfigOpt {
+	return func(c *Config) {
+		if c.OTLP.Resource == nil {
+			c.OTLP.Resource = make(map[string]string)
+		}
+		c.OTLP.Resource[key] = val
+	}
+}
+
+// WithOTLPGzip set whether the request body should be compressed using gzip.
+func WithOTLPGzip(b bool) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.Gzip = b
+	}
+}
+
+// WithOTLPClient set the http client used to export the logs.
+func WithOTLPClient(cl *http.Client) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.Client = cl
+	}
+}
+
+// WithOTLPBatch set how the logs are batched and retried.
+func WithOTLPBatch(b BatchConfig) ConfigOpt {
+	return func(c *Config) {
+		c.OTLP.Batch = b
+	}
+}
+
+// WithGELFAddr set the graylog GELF input network and address. Network is
+// either 'udp' or 'tcp'.
+func WithGELFAddr(network, addr string) ConfigOpt {
+	return func(c *Config) {
+		c.GELF.Network = network
+		c.GELF.Addr = addr
+	}
+}
+
+// WithGELFHost set the GELF host field.
+func WithGELFHost(host string) ConfigOpt {
+	return func(c *Config) {
+		c.GELF.Host = host
+	}
+}
+
+// WithGELFCompression set the UDP message compression.
+func WithGELFCompression(comp GELFCompression) ConfigOpt {
+	return func(c *Config) {
+		c.GELF.Compression = comp
+	}
+}
+
+// WithGELFChunkSize set the maximum UDP datagram size before the message is
+// sent in chunks.
+func WithGELFChunkSize(size int) ConfigOpt {
+	return func(c *Config) {
+		c.GELF.ChunkSize = size
+	}
+}
+
+// WithGELFMaxBackoff set the maximum delay between reconnection attempts.
+func WithGELFMaxBackoff(dur time.Duration) ConfigOpt {
+	return func(c *Config) {
+		c.GELF.MaxBackoff = dur
+	}
+}
@@ -1,8 +1,12 @@
 package log
 
 import (
+	"crypto/tls"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,18 +15,146 @@ func TestNewConfig(t *testing.T) {
 		cnf := NewConfig(
 			WithNRAppName("kpm-library"),
 			WithNRLicense("license"),
+			WithNRApp(&newrelic.Application{}),
 			WithFilePath("/var/log/app.log"),
 			WithFileSize(100),
 			WithFileAge(7),
 			WithFileMaxBackup(7),
+			WithFileRotate(FileRotateDaily),
+			WithFileCompress(true),
+			WithFileUTC(true),
+			WithFileSymlink(true),
+			WithFileReopen(true),
+			WithPlatformStderr(true),
+			WithSyslogAddr("tcp", "localhost:514"),
+			WithSyslogTLS(&tls.Config{}),
+			WithSyslogAppName("kpm-library"),
+			WithSyslogHostname("host-1"),
+			WithSyslogFacility(FacilityLocal7),
+			WithSyslogData("env", "prod"),
+			WithSyslogMaxBackoff(time.Minute),
+			WithHTTPURL("http://localhost:8080/logs"),
+			WithHTTPHeader("X-Api-Key", "secret"),
+			WithHTTPFormat(HTTPJSONArray),
+			WithHTTPGzip(true),
+			WithHTTPClient(http.DefaultClient),
+			WithHTTPBatch(BatchConfig{Size: 100}),
+			WithLokiURL("http://localhost:3100"),
+			WithLokiTenant("team-a"),
+			WithLokiBasicAuth("user", "pass"),
+			WithLokiLabel("app", "kpm"),
+			WithLokiLabelKeys("level", "app_env"),
+			WithLokiClient(http.DefaultClient),
+			WithLokiBatch(BatchConfig{Size: 200}),
+			WithElasticURL("http://localhost:9200"),
+			WithElasticIndex("app-logs-{2006.01.02}"),
+			WithElasticLocalTime(true),
+			WithElasticAPIKey("key"),
+			WithElasticBasicAuth("user", "pass"),
+			WithElasticOnFailure(func([]byte, error) {}),
+			WithElasticClient(http.DefaultClient),
+			WithElasticBatch(BatchConfig{Size: 300}),
+			WithFluentAddr("unix", "/var/run/fluent.sock"),
+			WithFluentTag("kpm.api"),
+			WithFluentMode(FluentPackedForward),
+			WithFluentAck(true),
+			WithFluentTimeout(time.Second),
+			WithFluentBatch(BatchConfig{Size: 400}),
+			WithOTLPURL("http://localhost:4318/v1/logs"),
+			WithOTLPEncoding(OTLPJSON),
+			WithOTLPHeader("X-Api-Key", "secret"),
+			WithOTLPServiceName("kpm"),
+			WithOTLPResource("deployment.environment", "prod"),
+			WithOTLPGzip(true),
+			WithOTLPClient(http.DefaultClient),
+			WithOTLPBatch(BatchConfig{Size: 500}),
+			WithGELFAddr("tcp", "localhost:12201"),
+			WithGELFHost("host-1"),
+			WithGELFCompression(GELFZlib),
+			WithGELFChunkSize(8192),
+			WithGELFMaxBackoff(time.Minute),
 		)
 
 		// assert all values
 		assert.Equal(t, "kpm-library", cnf.NR.Name)
 		assert.Equal(t, "license", cnf.NR.License)
+		assert.Equal(t, &newrelic.Application{}, cnf.NR.App)
 		assert.Equal(t, "/var/log/app.log", cnf.File.Path)
 		assert.Equal(t, 100, cnf.File.Size)
 		assert.Equal(t, 7, cnf.File.Age)
 		assert.Equal(t, 7, cnf.File.Num)
+		assert.Equal(t, FileRotateDaily, cnf.File.Rotate)
+		assert.True(t, cnf.File.Compress)
+		assert.True(t, cnf.File.UTC)
+		assert.True(t, cnf.File.Symlink)
+		assert.True(t, cnf.File.Reopen)
+		assert.True(t, cnf.Platform.Stderr)
+		assert.Equal(t, "tcp", cnf.Syslog.Network)
+		assert.Equal(t, "localhost:514", cnf.Syslog.Addr)
+		assert.NotNil(t, cnf.Syslog.TLS)
+		assert.Equal(t, "kpm-library", cnf.Syslog.AppName)
+		assert.Equal(t, "host-1", cnf.Syslog.Hostname)
+		assert.Equal(t, FacilityLocal7, *cnf.Syslog.Facility)
+		assert.Equal(t, map[string]string{"env": "prod"}, cnf.Syslog.Data)
+		assert.Equal(t, time.Minute, cnf.Syslog.MaxBackoff)
+		assert.Equal(t, "http://localhost:8080/logs", cnf.HTTP.URL)
+		assert.Equal(t, map[string]string{"X-Api-Key": "secret"}, cnf.HTTP.Headers)
+		assert.Equal(t, HTTPJSONArray, cnf.HTTP.Format)
+		assert.True(t, cnf.HTTP.Gzip)
+		assert.Equal(t, http.DefaultClient, cnf.HTTP.Client)
+		assert.Equal(t, 100, cnf.HTTP.Batch.Size)
+		assert.Equal(t, "http://localhost:3100", cnf.Loki.URL)
+		assert.Equal(t, "team-a", cnf.Loki.TenantID)
+		assert.Equal(t, "user", cnf.Loki.Username)
+		assert.Equal(t, "pass", cnf.Loki.Password)
+		assert.Equal(t, map[string]string{"app": "kpm"}, cnf.Loki.Labels)
+		assert.Equal(t, []string{"level", "app_env"}, cnf.Loki.LabelKeys)
+		assert.Equal(t, http.DefaultClient, cnf.Loki.Client)
+		assert.Equal(t, 200, cnf.Loki.Batch.Size)
+		assert.Equal(t, "http://localhost:9200", cnf.Elastic.URL)
+		assert.Equal(t, "app-logs-{2006.01.02}", cnf.Elastic.Index)
+		assert.True(t, cnf.Elastic.LocalTime)
+		assert.Equal(t, "key", cnf.Elastic.APIKey)
+		assert.Equal(t, "user", cnf.Elastic.Username)
+		assert.Equal(t, "pass", cnf.Elastic.Password)
+		assert.NotNil(t, cnf.Elastic.OnFailure)
+		assert.Equal(t, http.DefaultClient, cnf.Elastic.Client)
+		assert.Equal(t, 300, cnf.Elastic.Batch.Size)
+		assert.Equal(t, "unix", cnf.Fluent.Network)
+		assert.Equal(t, "/var/run/fluent.sock", cnf.Fluent.Addr)
+		assert.Equal(t, "kpm.api", cnf.Fluent.Tag)
+		assert.Equal(t, FluentPackedForward, cnf.Fluent.Mode)
+		assert.True(t, cnf.Fluent.Ack)
+		assert.Equal(t, time.Second, cnf.Fluent.Timeout)
+		assert.Equal(t, 400, cnf.Fluent.Batch.Size)
+		assert.Equal(t, "http://localhost:4318/v1/logs", cnf.OTLP.URL)
+		assert.Equal(t, OTLPJSON, cnf.OTLP.Encoding)
+		assert.Equal(t, map[string]string{"X-Api-Key": "secret"}, cnf.OTLP.Headers)
+		assert.Equal(t, "kpm", cnf.OTLP.ServiceName)
+		assert.Equal(t, map[string]string{"deployment.environment": "prod"}, cnf.OTLP.Resource)
+		assert.True(t, cnf.OTLP.Gzip)
+		assert.Equal(t, http.DefaultClient, cnf.OTLP.Client)
+		assert.Equal(t, 500, cnf.OTLP.Batch.Size)
+		assert.Equal(t, "tcp", cnf.GELF.Network)
+		assert.Equal(t, "localhost:12201", cnf.GELF.Addr)
+		assert.Equal(t, "host-1", cnf.GELF.Host)
+		assert.Equal(t, GELFZlib, cnf.GELF.Compression)
+		assert.Equal(t, 8192, cnf.GELF.ChunkSize)
+		assert.Equal(t, time.Minute, cnf.GELF.MaxBackoff)
 	})
 }
+
+func TestOutput_String(t *testing.T) {
+	assert.Equal(t, "console", CONSOLE.String())
+	assert.Equal(t, "newrelic", NEWRELIC.String())
+	assert.Equal(t, "file", FILE.String())
+	assert.Equal(t, "platform", PLATFORM.String())
+	assert.Equal(t, "syslog", SYSLOG.String())
+	assert.Equal(t, "http", HTTP.String())
+	assert.Equal(t, "loki", LOKI.String())
+	assert.Equal(t, "elastic", ELASTIC.String())
+	assert.Equal(t, "fluent", FLUENT.String())
+	assert.Equal(t, "otlp", OTLP.String())
+	assert.Equal(t, "gelf", GELF.String())
+	assert.Equal(t, "unknown", Output(-1).String())
+}
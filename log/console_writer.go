@@ -9,15 +9,16 @@ import (
 // NewConsoleWriter return Writer implementer that write logs to os.Stdout and
 // set given lvl as the log Level.
 func NewConsoleWriter(lvl Level) Writer {
-	return &consoleOutput{lvl: lvl}
+	return &consoleOutput{lvl: NewAtomicLevel(lvl)}
 }
 
 type consoleOutput struct {
-	lvl Level
+	lvl *AtomicLevel
 }
 
 func (c *consoleOutput) Writer() io.Writer     { return os.Stdout }
 func (c *consoleOutput) Output() Output        { return CONSOLE }
-func (c *consoleOutput) Level() Level          { return c.lvl }
+func (c *consoleOutput) Level() Level          { return c.lvl.Level() }
+func (c *consoleOutput) SetLevel(lvl Level)    { c.lvl.SetLevel(lvl) }
 func (c *consoleOutput) Wait(_ time.Duration)  {}
 func (c *consoleOutput) Flush(_ time.Duration) {}
@@ -2,7 +2,9 @@ package log
 
 import (
 	"context"
-	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // loggerKeyType custom type for log type inside context.
@@ -11,11 +13,28 @@ type loggerKeyType int
 // loggerKey identifier for logger inside context.
 const loggerKey loggerKeyType = iota
 
-// singletonLogger holder of Logger and intended to be used as singleton.
-var singletonLogger Logger
+// defaultLogger holder of the process-wide Logger.
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault set given l as the process-wide Logger that is returned by
+// Default and FromCtx when there is no Logger inside the context. Passing nil
+// will unset it.
+func SetDefault(l Logger) {
+	if l == nil {
+		defaultLogger.Store(nil)
+		return
+	}
+	defaultLogger.Store(&l)
+}
 
-// mutex to protect singletonLogger.
-var mutex sync.Mutex
+// Default return the process-wide Logger set by SetDefault or a no-op Logger
+// if it's not set yet.
+func Default() Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return *l
+	}
+	return NewNop()
+}
 
 // WithCtx return a copy of ctx with given logger attached.
 func WithCtx(ctx context.Context, w Logger) context.Context {
@@ -24,26 +43,39 @@ func WithCtx(ctx context.Context, w Logger) context.Context {
 	}
 	if ww, ok := ctx.Value(loggerKey).(Logger); ok {
 		// do not store same Logger
-		if ww == singletonLogger {
+		if ww == w {
 			return ctx
 		}
 	}
 	return context.WithValue(ctx, loggerKey, w)
 }
 
-// FromCtx return the singleton Logger associated with given ctx. If no
-// logger is associated, the default logger is returned, unless it is nil in
-// which case a no-op logger is returned.
+// FromCtx return the Logger associated with given ctx. If no logger is
+// associated, the default logger is returned, unless it is nil in which case
+// a no-op logger is returned.
 func FromCtx(ctx context.Context) Logger {
 	if ww, ok := ctx.Value(loggerKey).(Logger); ok {
-		// singleton already set
-		if singletonLogger != nil {
-			// and the value is same with the Logger inside context
-			if ww == singletonLogger {
-				return singletonLogger
-			}
-		}
 		return ww
 	}
-	return NewNop()
+	return Default()
+}
+
+// CtxExtractor extract Log(s) from the given context such as user id or
+// tenant, that will be added to the log entry by Logger.Ctx.
+type CtxExtractor func(ctx context.Context) []Log
+
+// ctxFields return the trace_id and span_id of the OpenTelemetry span inside
+// given ctx if any, followed by the Log(s) from given extractors.
+func ctxFields(ctx context.Context, extractors []CtxExtractor) []Log {
+	var pr []Log
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		pr = append(pr,
+			String("trace_id", sc.TraceID().String()),
+			String("span_id", sc.SpanID().String()),
+		)
+	}
+	for _, ex := range extractors {
+		pr = append(pr, ex(ctx)...)
+	}
+	return pr
 }
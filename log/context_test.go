@@ -2,50 +2,82 @@ package log
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
+func TestSetDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	t.Run("Should return no-op Logger if default is not set", func(t *testing.T) {
+		SetDefault(nil)
+		assert.Equal(t, &nopLogger{}, Default())
+	})
+	t.Run("Should return the Logger that set as default", func(t *testing.T) {
+		sl := NewSlogLogger()
+		SetDefault(sl)
+		assert.Equal(t, sl, Default())
+	})
+}
+
 func TestWithCtx(t *testing.T) {
 	t.Run("Should return exactly same context if given Logger is nil", func(t *testing.T) {
 		expCtx := context.Background()
 		ctx := WithCtx(expCtx, nil)
 		assert.Equal(t, expCtx, ctx)
 	})
-	t.Run("Should equal with given Logger if singleton instance not assigned yet", func(t *testing.T) {
+	t.Run("Should equal with given Logger", func(t *testing.T) {
 		pd := NewNop()
 		ctx := WithCtx(context.Background(), pd)
-		if pr, ok := ctx.Value(loggerKey).(Logger); ok {
-			assert.Equal(t, pr, pd)
-		}
-	})
-	t.Run("Should not equal with given Logger if singleton instance already set", func(t *testing.T) {
-		singletonLogger = NewNop()
-		ctx := WithCtx(context.Background(), nopLogger{})
-		if pr, ok := ctx.Value(loggerKey).(Logger); ok {
-			assert.NotEqual(t, pr, singletonLogger)
-		}
+		pr, ok := ctx.Value(loggerKey).(Logger)
+		require.True(t, ok)
+		assert.Equal(t, pr, pd)
 	})
-	t.Run("Should be equal if given Logger and singleton instance is same", func(t *testing.T) {
+	t.Run("Should be equal if given Logger is already inside context", func(t *testing.T) {
 		pd := NewNop()
-		singletonLogger = pd
 		parentCtx := WithCtx(context.Background(), pd)
 
 		childCtx := WithCtx(parentCtx, pd)
 		assert.Equal(t, parentCtx, childCtx)
 	})
+	t.Run("Should replace the Logger inside context with the child Logger", func(t *testing.T) {
+		zl := NewZapLogger()
+		zl.Init(time.Microsecond)
+		parentCtx := WithCtx(context.Background(), zl)
+
+		child := zl.With(String("hello", "world"))
+		childCtx := WithCtx(parentCtx, child)
+		assert.NotEqual(t, parentCtx, childCtx)
+		assert.Same(t, child, FromCtx(childCtx))
+		assert.Same(t, zl, FromCtx(parentCtx))
+	})
 }
 
 func TestFromCtx(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
 	t.Run("Should return no-op Logger if no Logger in given context", func(t *testing.T) {
+		SetDefault(nil)
 		l := FromCtx(context.Background())
 		assert.NotNil(t, l)
 		assert.Equal(t, &nopLogger{}, l)
 		assert.IsType(t, &nopLogger{}, l)
+		assert.NotPanics(t, func() { l.With(String("k", "v")).Inf("msg") })
+	})
+	t.Run("Should return default Logger if no Logger in given context", func(t *testing.T) {
+		sl := NewSlogLogger()
+		SetDefault(sl)
+		l := FromCtx(context.Background())
+		assert.Equal(t, sl, l)
 	})
-	t.Run("Should return given Logger inside context if singleton is not set", func(t *testing.T) {
-		singletonLogger = nil
+	t.Run("Should return given Logger inside context", func(t *testing.T) {
+		SetDefault(NewSlogLogger())
 		nl := NewNop()
 		ctx := WithCtx(context.Background(), nl)
 		l := FromCtx(ctx)
@@ -53,12 +85,101 @@ func TestFromCtx(t *testing.T) {
 		assert.Equal(t, nl, l)
 		assert.IsType(t, &nopLogger{}, l)
 	})
-	t.Run("Should return singleton Logger inside context if exist and singleton already set", func(t *testing.T) {
-		sl := NewSlogLogger()
-		ctx := WithCtx(context.Background(), sl)
-		l := FromCtx(ctx)
-		assert.NotNil(t, l)
-		assert.Equal(t, sl, l)
-		assert.IsType(t, &slogLogger{}, l)
+}
+
+func TestConcurrentRequestScopedLogger(t *testing.T) {
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(func() { SetDefault(nil) })
+			writer, obs := NewObserverWriter(DebugLevel, FILE)
+			wr := fn(writer)
+			wr.Init(time.Microsecond)
+			SetDefault(wr)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					id := strconv.Itoa(i)
+					ctx := WithCtx(context.Background(), Default().With(String("request_id", id)))
+					ctx = WithCtx(ctx, FromCtx(ctx).Group("req", String("id", id)))
+					FromCtx(ctx).Inf(id)
+				}(i)
+			}
+			wg.Wait()
+			// the parent logger is not affected by any of the child logger
+			Default().Inf("parent")
+
+			logs := obs.All()
+			require.Len(t, logs, 51)
+			for _, l := range logs[:50] {
+				assert.Equal(t, l.msg, l.Get("request_id"))
+				assert.Equal(t, map[string]any{"id": l.msg}, l.Get("req"))
+			}
+			assert.True(t, logs[50].EqualMsg("parent"))
+			assert.Nil(t, logs[50].Get("request_id"))
+			assert.Nil(t, logs[50].Get("req"))
+		})
+	}
+}
+
+func TestCtxFields(t *testing.T) {
+	t.Run("Should return nothing if there is no span nor extractor", func(t *testing.T) {
+		assert.Empty(t, ctxFields(context.Background(), nil))
 	})
+	t.Run("Should return trace_id & span_id from the span context and Log(s) from extractors", func(t *testing.T) {
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{0x01},
+			SpanID:  trace.SpanID{0x02},
+		}))
+		ex := func(ctx context.Context) []Log {
+			return []Log{String("tenant", "acme")}
+		}
+		assert.Equal(t, []Log{
+			String("trace_id", "01000000000000000000000000000000"),
+			String("span_id", "0200000000000000"),
+			String("tenant", "acme"),
+		}, ctxFields(ctx, []CtxExtractor{ex}))
+	})
+}
+
+func TestLoggerCtx(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	type userKey struct{}
+	ex := func(ctx context.Context) []Log {
+		if id, ok := ctx.Value(userKey{}).(string); ok {
+			return []Log{String("user_id", id)}
+		}
+		return nil
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(DebugLevel, FILE)
+			wr := fn([]Writer{writer}, WithCtxExtractor(ex))
+			wr.Init(time.Microsecond)
+
+			// nothing to be added
+			assert.Same(t, wr, wr.Ctx(context.Background()))
+
+			ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID{0xab},
+				SpanID:  trace.SpanID{0xcd},
+			}))
+			ctx = context.WithValue(ctx, userKey{}, "007")
+			wr.Ctx(ctx).Inf("with ctx")
+			wr.Inf("without ctx")
+
+			logs := obs.TakeAll()
+			require.Len(t, logs, 2)
+			assert.Equal(t, "ab000000000000000000000000000000", logs[0].Get("trace_id"))
+			assert.Equal(t, "cd00000000000000", logs[0].Get("span_id"))
+			assert.Equal(t, "007", logs[0].Get("user_id"))
+			assert.Nil(t, logs[1].Get("trace_id"))
+			assert.Nil(t, logs[1].Get("user_id"))
+		})
+	}
 }
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewElasticWriter return Writer implementer that index logs in batch to
+// Elasticsearch or OpenSearch through the _bulk api by given Config.Elastic
+// and set given lvl as the log Level. Only the rejected documents are
+// retried, and every dropped or failed document is reported to
+// Config.Elastic.OnFailure.
+func NewElasticWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	e := &elasticOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.Elastic}
+	// set default value
+	if e.cnf.Client == nil {
+		e.cnf.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if e.cnf.Index == "" {
+		e.cnf.Index = "logs-{2006.01.02}"
+	}
+	e.url = strings.TrimSuffix(e.cnf.URL, "/") + "/_bulk"
+	// report the dropped lines as failed documents as well, before the
+	// batcher starts since the leftover spill file may be dropped right away
+	batch := e.cnf.Batch
+	if onDrop := batch.OnDrop; e.cnf.OnFailure != nil {
+		batch.OnDrop = func(lines [][]byte, err error) {
+			for _, doc := range lines {
+				e.failure(doc, err)
+			}
+			if onDrop != nil {
+				onDrop(lines, err)
+			}
+		}
+	}
+	e.bt = newBatcher(batch, "elastic-"+shortHash(e.url+e.cnf.Index), e.send, stampedEntry)
+	return e
+}
+
+type elasticOutput struct {
+	lvl *AtomicLevel
+	cnf ElasticConfig
+	url string
+	bt  *batcher
+}
+
+// Write implement io.Writer. The entry time is queued along given p, so the
+// document is indexed based on its original time even when it's spilled to
+// disk.
+func (e *elasticOutput) Write(p []byte) (int, error) {
+	ts := strconv.FormatInt(entryTime(p, time.Now()).UnixNano(), 10)
+	e.bt.add(append([]byte(ts+" "), bytes.TrimSpace(p)...))
+	return len(p), nil
+}
+
+// elasticBulkResponse the _bulk api response body.
+type elasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// send index given lines as a single _bulk request. Return partialError that
+// hold only the rejected documents which are worth to retry, while the other
+// failed documents are reported to the failure callback.
+func (e *elasticOutput) send(ctx context.Context, lines [][]byte) error {
+	var body bytes.Buffer
+	for _, l := range lines {
+		ts, doc, _ := bytes.Cut(l, []byte{' '})
+		nsec, _ := strconv.ParseInt(string(ts), 10, 64)
+		action, _ := json.Marshal(map[string]map[string]string{"create": {"_index": e.index(time.Unix(0, nsec))}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(doc)
+		body.WriteByte('\n')
+	}
+
+	req, err := newBatchRequest(ctx, e.url, body.Bytes(), false)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if e.cnf.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.cnf.APIKey)
+	} else if e.cnf.Username != "" || e.cnf.Password != "" {
+		req.SetBasicAuth(e.cnf.Username, e.cnf.Password)
+	}
+
+	res, err := e.cnf.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return checkBatchResponse(res)
+	}
+
+	var bulk elasticBulkResponse
+	if err = json.NewDecoder(res.Body).Decode(&bulk); err != nil {
+		return &permanentError{fmt.Errorf("failed to decode bulk response: %w", err)}
+	}
+	if !bulk.Errors {
+		return nil
+	}
+
+	var retry [][]byte
+	for i, item := range bulk.Items {
+		if i >= len(lines) {
+			break
+		}
+		for _, it := range item {
+			switch {
+			case it.Status >= 200 && it.Status < 300:
+			case it.Status == http.StatusTooManyRequests || it.Status >= 500:
+				retry = append(retry, lines[i])
+			default:
+				_, doc, _ := bytes.Cut(lines[i], []byte{' '})
+				e.failure(doc, fmt.Errorf("document is rejected with status %d: %s", it.Status, it.Error))
+			}
+		}
+	}
+	if len(retry) > 0 {
+		return &partialError{lines: retry, err: fmt.Errorf("%d documents are rejected", len(retry))}
+	}
+	return nil
+}
+
+// index return the index name for the document at given t.
+func (e *elasticOutput) index(t time.Time) string {
+	i := strings.IndexByte(e.cnf.Index, '{')
+	j := strings.LastIndexByte(e.cnf.Index, '}')
+	if i < 0 || j < i {
+		return e.cnf.Index
+	}
+	if !e.cnf.LocalTime {
+		t = t.UTC()
+	}
+	return e.cnf.Index[:i] + t.Format(e.cnf.Index[i+1:j]) + e.cnf.Index[j+1:]
+}
+
+// failure report given doc to the failure callback if any.
+func (e *elasticOutput) failure(doc []byte, err error) {
+	if e.cnf.OnFailure != nil {
+		e.cnf.OnFailure(doc, err)
+	}
+}
+
+func (e *elasticOutput) Writer() io.Writer             { return e }
+func (e *elasticOutput) Output() Output                { return ELASTIC }
+func (e *elasticOutput) Level() Level                  { return e.lvl.Level() }
+func (e *elasticOutput) SetLevel(lvl Level)            { e.lvl.SetLevel(lvl) }
+func (e *elasticOutput) Wait(_ time.Duration)          {}
+func (e *elasticOutput) Flush(dur time.Duration)       { e.bt.flush(dur) }
+func (e *elasticOutput) LastError() (time.Time, error) { return e.bt.lastError() }
+
+// addDropHook implement dropReporter.
+func (e *elasticOutput) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	e.bt.addDropHook(key, fn)
+}
@@ -0,0 +1,134 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewElasticWriter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]string // the msg of each document per request
+		indices  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "ApiKey secret", r.Header.Get("Authorization"))
+
+		var msgs []string
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			var action map[string]map[string]string
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &action))
+			require.True(t, sc.Scan())
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &doc))
+
+			mu.Lock()
+			indices = append(indices, action["create"]["_index"])
+			mu.Unlock()
+			msgs = append(msgs, doc["msg"].(string))
+		}
+
+		mu.Lock()
+		requests = append(requests, msgs)
+		first := len(requests) == 1
+		mu.Unlock()
+
+		// on the first request, reject the second document temporarily and
+		// the third document permanently
+		var items []string
+		for i := range msgs {
+			status := 201
+			if first && i == 1 {
+				status = 429
+			}
+			if first && i == 2 {
+				status = 400
+			}
+			items = append(items, fmt.Sprintf(`{"create":{"status":%d,"error":{"type":"oops"}}}`, status))
+		}
+		fmt.Fprintf(w, `{"errors":%t,"items":[%s]}`, first, strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	var failed []string
+	cnf := NewConfig(
+		WithElasticURL(srv.URL),
+		WithElasticIndex("app-logs-{2006.01.02}"),
+		WithElasticAPIKey("secret"),
+		WithElasticOnFailure(func(doc []byte, err error) {
+			failed = append(failed, string(doc))
+			assert.ErrorContains(t, err, "status 400")
+		}),
+		WithElasticBatch(BatchConfig{Size: 10, Interval: time.Hour, MinBackoff: time.Millisecond}),
+	)
+	ew := NewElasticWriter(DebugLevel, cnf)
+	assert.Equal(t, ELASTIC, ew.Output())
+
+	wr := NewZapLogger(ew)
+	wr.Init(time.Second)
+	wr.Inf("first")
+	wr.Inf("second")
+	wr.Inf("third")
+	wr.Flush(time.Second)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{"first", "second", "third"}, requests[0])
+	// only the temporarily rejected document is retried
+	assert.Equal(t, []string{"second"}, requests[1])
+	require.Len(t, failed, 1)
+	assert.Contains(t, failed[0], `"msg":"third"`)
+	assert.Equal(t, "app-logs-"+time.Now().UTC().Format("2006.01.02"), indices[0])
+}
+
+func TestElasticWriterEntryTime(t *testing.T) {
+	var index string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var action map[string]map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&action))
+		index = action["create"]["_index"]
+		fmt.Fprint(w, `{"errors":false,"items":[{"create":{"status":201}}]}`)
+	}))
+	defer srv.Close()
+
+	ew := NewElasticWriter(DebugLevel, NewConfig(
+		WithElasticURL(srv.URL),
+		WithElasticBatch(BatchConfig{Size: 10, Interval: time.Hour}),
+	))
+	ew.Writer().Write([]byte(`{"level":"INFO","time":"2023-09-22T13:38:39+07:00","msg":"late"}` + "\n"))
+	ew.Flush(time.Second)
+	assert.Equal(t, "logs-2023.09.22", index)
+}
+
+func TestElasticIndex(t *testing.T) {
+	at := time.Date(2026, 10, 19, 5, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	testCases := []struct {
+		name   string
+		index  string
+		local  bool
+		expect string
+	}{
+		{name: "Daily index in UTC", index: "app-logs-{2006.01.02}", expect: "app-logs-2026.10.18"},
+		{name: "Daily index in local time", index: "app-logs-{2006.01.02}", local: true, expect: "app-logs-2026.10.19"},
+		{name: "Monthly index with suffix", index: "app-{2006.01}-v1", expect: "app-2026.10-v1"},
+		{name: "Static index", index: "app-logs", expect: "app-logs"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &elasticOutput{cnf: ElasticConfig{Index: tc.index, LocalTime: tc.local}}
+			assert.Equal(t, tc.expect, e.index(at))
+		})
+	}
+}
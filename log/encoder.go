@@ -0,0 +1,425 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// EncoderFormat define how the log entry is encoded.
+type EncoderFormat int8
+
+const (
+	JSONFormat    EncoderFormat = iota // JSONFormat encode the log entry as single-line JSON
+	ConsoleFormat                      // ConsoleFormat encode the log entry as tab separated human-readable line
+	LogfmtFormat                       // LogfmtFormat encode the log entry as logfmt key=value pairs
+)
+
+// consoleTimeFormat the default time layout of ConsoleFormat.
+const consoleTimeFormat = "2006-01-02T15:04:05.000Z0700"
+
+// Encoder define how the log entry is encoded for a Writer. The log entry is
+// encoded the same way regardless of the Logger backend. Note that the Writer
+// that read the log entry such as LOKI, ELASTIC or OTLP expect the default
+// JSON encoding, while PLATFORM and SYSLOG always use it.
+type Encoder struct {
+	// Format default to JSONFormat.
+	Format EncoderFormat
+	// TimeKey the key of the entry time. Default to 'time'.
+	TimeKey string
+	// LevelKey the key of the entry level. Default to 'level'.
+	LevelKey string
+	// MessageKey the key of the entry message. Default to 'msg'.
+	MessageKey string
+	// TimeFormat the Go time layout of the entry time. Default to
+	// time.RFC3339 or '2006-01-02T15:04:05.000Z0700' for ConsoleFormat.
+	TimeFormat string
+	// LowercaseLevel encode the level in lower case such as 'info' instead of
+	// 'INFO'.
+	LowercaseLevel bool
+	// Color colorize the level. Only used by ConsoleFormat.
+	Color bool
+}
+
+// EncoderProvider optional interface that may be implemented by Writer to
+// declare its own Encoder instead of the default one based on its Output.
+type EncoderProvider interface {
+	// Encoder return how the log entry should be encoded.
+	Encoder() Encoder
+}
+
+// defaultConsoleEncoder the Encoder used by CONSOLE Writer by default.
+var defaultConsoleEncoder = Encoder{Format: ConsoleFormat, Color: true}
+
+// withDefault return a copy of e with the default value set.
+func (e Encoder) withDefault() Encoder {
+	if e.TimeKey == "" {
+		e.TimeKey = "time"
+	}
+	if e.LevelKey == "" {
+		e.LevelKey = "level"
+	}
+	if e.MessageKey == "" {
+		e.MessageKey = "msg"
+	}
+	if e.TimeFormat == "" {
+		e.TimeFormat = time.RFC3339
+		if e.Format == ConsoleFormat {
+			e.TimeFormat = consoleTimeFormat
+		}
+	}
+	return e
+}
+
+// encodedEntry the log entry metadata that is encoded before the fields.
+type encodedEntry struct {
+	time                    time.Time
+	level                   Level
+	msg                     string
+	caller, function, stack string
+}
+
+// appendEntry append given ent along with its natively encoded fields to buf.
+// The time, level, caller, function and message always come first in that
+// order, followed by the fields and the stack trace, so the output doesn't
+// depend on the backend.
+func (e Encoder) appendEntry(buf []byte, ent *encodedEntry, fields []byte) []byte {
+	var tm string
+	if !ent.time.IsZero() {
+		tm = ent.time.Format(e.TimeFormat)
+	}
+	lvl := ent.level.String()
+	if e.LowercaseLevel {
+		lvl = strings.ToLower(lvl)
+	}
+
+	switch e.Format {
+	case ConsoleFormat:
+		buf = e.appendConsole(buf, ent, tm, lvl, fields)
+	case LogfmtFormat:
+		buf = e.appendLogfmt(buf, ent, tm, lvl, fields)
+	default:
+		buf = e.appendJSON(buf, ent, tm, lvl, fields)
+	}
+	return append(buf, '\n')
+}
+
+// appendJSON append given ent as single-line JSON.
+func (e Encoder) appendJSON(buf []byte, ent *encodedEntry, tm, lvl string, fields []byte) []byte {
+	f := fieldEncoder{buf: append(buf, '{')}
+	if tm != "" {
+		f.addString(e.TimeKey, tm)
+	}
+	f.addString(e.LevelKey, lvl)
+	if ent.caller != "" {
+		f.addString("caller", ent.caller)
+	}
+	if ent.function != "" {
+		f.addString("function", ent.function)
+	}
+	f.addString(e.MessageKey, ent.msg)
+	if len(fields) > 0 {
+		f.buf = append(append(f.buf, ','), fields...)
+	}
+	if ent.stack != "" {
+		f.addString("stacktrace", ent.stack)
+	}
+	return append(f.buf, '}')
+}
+
+// appendConsole append given ent as tab separated line followed by the fields
+// as JSON and the stack trace in the next lines.
+func (e Encoder) appendConsole(buf []byte, ent *encodedEntry, tm, lvl string, fields []byte) []byte {
+	if e.Color {
+		lvl = consoleColor(ent.level) + lvl + "\x1b[0m"
+	}
+	start := len(buf)
+	for _, s := range []string{tm, lvl, ent.caller, ent.function, ent.msg} {
+		if s == "" {
+			continue
+		}
+		if len(buf) > start {
+			buf = append(buf, '\t')
+		}
+		buf = append(buf, s...)
+	}
+	if len(fields) > 0 {
+		buf = append(buf, "\t{"...)
+		buf = append(append(buf, fields...), '}')
+	}
+	if ent.stack != "" {
+		buf = append(append(buf, '\n'), ent.stack...)
+	}
+	return buf
+}
+
+// appendLogfmt append given ent as logfmt key=value pairs.
+func (e Encoder) appendLogfmt(buf []byte, ent *encodedEntry, tm, lvl string, fields []byte) []byte {
+	f := fieldEncoder{logfmt: true, buf: buf, start: len(buf)}
+	if tm != "" {
+		f.addString(e.TimeKey, tm)
+	}
+	f.addString(e.LevelKey, lvl)
+	if ent.caller != "" {
+		f.addString("caller", ent.caller)
+	}
+	if ent.function != "" {
+		f.addString("function", ent.function)
+	}
+	f.addString(e.MessageKey, ent.msg)
+	if len(fields) > 0 {
+		f.buf = append(append(f.buf, ' '), fields...)
+	}
+	if ent.stack != "" {
+		f.addString("stacktrace", ent.stack)
+	}
+	return f.buf
+}
+
+// fieldEncoder natively encode the log fields, either as comma separated JSON
+// object members or as space separated logfmt pairs where the nested object
+// is flattened by joining the keys with '.'. This is shared by both zap and
+// slog backend, so they produce identical output.
+type fieldEncoder struct {
+	logfmt bool
+	buf    []byte
+	// start the position in buf where the fields start
+	start int
+	// prefix the logfmt key prefix of the current nested object
+	prefix string
+}
+
+// key append the separator from the previous field and given k.
+func (f *fieldEncoder) key(k string) {
+	if f.logfmt {
+		if len(f.buf) > f.start {
+			f.buf = append(f.buf, ' ')
+		}
+		f.buf = appendLogfmtKey(f.buf, f.prefix+k)
+		f.buf = append(f.buf, '=')
+		return
+	}
+	if n := len(f.buf); n > f.start && f.buf[n-1] != '{' {
+		f.buf = append(f.buf, ',')
+	}
+	f.buf = appendJSONString(f.buf, k)
+	f.buf = append(f.buf, ':')
+}
+
+func (f *fieldEncoder) addString(k, v string) {
+	f.key(k)
+	if f.logfmt {
+		f.buf = appendLogfmtValue(f.buf, v)
+		return
+	}
+	f.buf = appendJSONString(f.buf, v)
+}
+func (f *fieldEncoder) addInt(k string, v int64) {
+	f.key(k)
+	f.buf = strconv.AppendInt(f.buf, v, 10)
+}
+func (f *fieldEncoder) addUint(k string, v uint64) {
+	f.key(k)
+	f.buf = strconv.AppendUint(f.buf, v, 10)
+}
+func (f *fieldEncoder) addBool(k string, v bool) {
+	f.key(k)
+	f.buf = strconv.AppendBool(f.buf, v)
+}
+
+func (f *fieldEncoder) addFloat(k string, v float64, bits int) {
+	if s, ok := nonFiniteFloat(v); ok {
+		f.addString(k, s)
+		return
+	}
+	f.key(k)
+	f.buf = appendJSONFloat(f.buf, v, bits)
+}
+
+// addJSON add given JSON encoded value such as array or reflected value. In
+// logfmt the object is flattened, the string is unquoted and the array is kept
+// as quoted JSON.
+func (f *fieldEncoder) addJSON(k string, v []byte) {
+	if !f.logfmt || len(v) == 0 {
+		f.key(k)
+		f.buf = append(f.buf, v...)
+		return
+	}
+	switch v[0] {
+	case '{':
+		if members, err := splitJSONObject(v); err == nil {
+			prev := f.openObject(k)
+			for _, m := range members {
+				f.addJSON(m.key, m.val)
+			}
+			f.closeObject(prev)
+			return
+		}
+	case '"':
+		f.addString(k, jsonString(v))
+		return
+	case '[':
+		f.addString(k, string(v))
+		return
+	}
+	// number, bool and null
+	f.key(k)
+	f.buf = append(f.buf, v...)
+}
+
+// openObject start nested object of given k, return the previous logfmt
+// prefix that should be passed to closeObject.
+func (f *fieldEncoder) openObject(k string) string {
+	prev := f.prefix
+	if f.logfmt {
+		f.prefix += k + "."
+		return prev
+	}
+	f.key(k)
+	f.buf = append(f.buf, '{')
+	return prev
+}
+
+// closeObject end the nested object started by openObject.
+func (f *fieldEncoder) closeObject(prev string) {
+	if f.logfmt {
+		f.prefix = prev
+		return
+	}
+	f.buf = append(f.buf, '}')
+}
+
+// appendLogfmtKey append given key that has the space, '=' and '"' replaced.
+func appendLogfmtKey(buf []byte, key string) []byte {
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' {
+			r = '_'
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf
+}
+
+// appendLogfmtValue append given s as logfmt value that is quoted only if
+// needed.
+func appendLogfmtValue(buf []byte, s string) []byte {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+	}) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// appendJSONString append given s as JSON string the same way as
+// encoding/json, but without escaping the HTML characters.
+func appendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// nonFiniteFloat return the string representation of given f if it's NaN or
+// infinity, since JSON does not support it.
+func nonFiniteFloat(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", true
+	case math.IsInf(f, 1):
+		return "+Inf", true
+	case math.IsInf(f, -1):
+		return "-Inf", true
+	}
+	return "", false
+}
+
+// appendJSONFloat append given finite f the same way as encoding/json.
+func appendJSONFloat(buf []byte, f float64, bits int) []byte {
+	abs, format := math.Abs(f), byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// marshalJSON return given v encoded as JSON without escaping the HTML
+// characters, the same way as both zap and slog encode the reflected value.
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// consoleColor return the ANSI color of given lvl which is the same as zap.
+func consoleColor(lvl Level) string {
+	switch lvl {
+	case TraceLevel, DebugLevel:
+		return "\x1b[35m" // magenta
+	case InfoLevel:
+		return "\x1b[34m" // blue
+	case WarnLevel:
+		return "\x1b[33m" // yellow
+	}
+	return "\x1b[31m" // red
+}
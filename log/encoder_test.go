@@ -0,0 +1,257 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferWriter Writer implementer that write logs to memory buffer.
+type bufferWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	out Output
+}
+
+func (b *bufferWriter) Writer() io.Writer     { return b }
+func (b *bufferWriter) Output() Output        { return b.out }
+func (b *bufferWriter) Level() Level          { return TraceLevel }
+func (b *bufferWriter) Wait(_ time.Duration)  {}
+func (b *bufferWriter) Flush(_ time.Duration) {}
+func (b *bufferWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+func (b *bufferWriter) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// encodeSample encode the same sample log entry natively using both zap and
+// slog backend given enc.
+func encodeSample(t *testing.T, enc Encoder, msg string, withFields bool) (string, string) {
+	t.Helper()
+	at := time.Date(2023, 9, 22, 13, 38, 39, 123456789, time.FixedZone("", 7*60*60))
+
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: at, Message: msg}
+	var fields []zapcore.Field
+	if withFields {
+		ent.Caller = zapcore.EntryCaller{Defined: true, File: "/go/app/main.go", Line: 21, Function: "main.main"}
+		ent.Stack = "main.main\n\t/app/main.go:21"
+		fields = []zapcore.Field{
+			zap.Int("id", 7), zap.Bool("ok", true), zap.Any("nil", nil), zap.Strings("tags", []string{"a b"}),
+			zap.Dict("user", zap.String("name", "kpm"), zap.Dict("role", zap.Int("id", 1))),
+		}
+	}
+	zb, err := newZapEncoder(enc).EncodeEntry(ent, fields)
+	require.NoError(t, err)
+
+	var sb bytes.Buffer
+	r := slog.NewRecord(at, slog.LevelWarn, msg, 0)
+	if withFields {
+		r.AddAttrs(
+			slog.Int("id", 7), slog.Bool("ok", true), slog.Any("nil", nil), slog.Any("tags", []string{"a b"}),
+			slog.Group("user", slog.String("name", "kpm"), slog.Group("role", slog.Int("id", 1))),
+			slogMetaAttr("caller", "app/main.go:21"), slogMetaAttr("function", "main.main"),
+			slogMetaAttr("stacktrace", "main.main\n\t/app/main.go:21"),
+		)
+	}
+	require.NoError(t, newSlogEncoderHandler(&sb, enc, slog.LevelDebug).Handle(context.Background(), r))
+	return zb.String(), sb.String()
+}
+
+func TestEncoder_Native(t *testing.T) {
+	testCases := []struct {
+		name   string
+		enc    Encoder
+		msg    string
+		fields bool
+		expect string
+	}{
+		{
+			name:   "JSON with default config",
+			msg:    "hello world",
+			fields: true,
+			expect: `{"time":"2023-09-22T13:38:39+07:00","level":"WARN","caller":"app/main.go:21","function":"main.main","msg":"hello world","id":7,"ok":true,"nil":null,"tags":["a b"],"user":{"name":"kpm","role":{"id":1}},"stacktrace":"main.main\n\t/app/main.go:21"}` + "\n",
+		},
+		{
+			name:   "JSON with custom keys, time format and level casing",
+			enc:    Encoder{TimeKey: "ts", LevelKey: "severity", MessageKey: "message", TimeFormat: time.RFC3339Nano, LowercaseLevel: true},
+			msg:    "hi",
+			expect: `{"ts":"2023-09-22T13:38:39.123456789+07:00","severity":"warn","message":"hi"}` + "\n",
+		},
+		{
+			name:   "Logfmt",
+			enc:    Encoder{Format: LogfmtFormat, LowercaseLevel: true},
+			msg:    "hello world",
+			fields: true,
+			expect: `time=2023-09-22T13:38:39+07:00 level=warn caller=app/main.go:21 function=main.main msg="hello world" id=7 ok=true nil=null tags="[\"a b\"]" user.name=kpm user.role.id=1 stacktrace="main.main\n\t/app/main.go:21"` + "\n",
+		},
+		{
+			name:   "Logfmt should quote empty value",
+			enc:    Encoder{Format: LogfmtFormat, TimeFormat: "2006"},
+			expect: `time=2023 level=WARN msg=""` + "\n",
+		},
+		{
+			name:   "Console",
+			enc:    Encoder{Format: ConsoleFormat},
+			msg:    "hello world",
+			fields: true,
+			expect: "2023-09-22T13:38:39.123+0700\tWARN\tapp/main.go:21\tmain.main\thello world\t" +
+				`{"id":7,"ok":true,"nil":null,"tags":["a b"],"user":{"name":"kpm","role":{"id":1}}}` +
+				"\nmain.main\n\t/app/main.go:21\n",
+		},
+		{
+			name:   "Console with color",
+			enc:    defaultConsoleEncoder,
+			msg:    "oops",
+			expect: "2023-09-22T13:38:39.123+0700\t\x1b[33mWARN\x1b[0m\toops\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapOut, slogOut := encodeSample(t, tc.enc, tc.msg, tc.fields)
+			assert.Equal(t, tc.expect, zapOut)
+			assert.Equal(t, tc.expect, slogOut)
+		})
+	}
+}
+
+func TestFieldEncoder(t *testing.T) {
+	t.Run("Should escape JSON string without escaping HTML", func(t *testing.T) {
+		f := fieldEncoder{}
+		f.addString("a\"b", "<\n\x01\u2028\xff>")
+		assert.Equal(t, `"a\"b":"<\n\u0001\u2028\ufffd>"`, string(f.buf))
+	})
+	t.Run("Should encode float the same way as encoding/json", func(t *testing.T) {
+		f := fieldEncoder{}
+		f.addFloat("a", 1.5, 64)
+		f.addFloat("b", 1e21, 64)
+		f.addFloat("c", 1e-7, 64)
+		f.addFloat("d", math.NaN(), 64)
+		f.addFloat("e", math.Inf(-1), 64)
+		assert.Equal(t, `"a":1.5,"b":1e+21,"c":1e-7,"d":"NaN","e":"-Inf"`, string(f.buf))
+	})
+	t.Run("Should flatten the reflected object in logfmt", func(t *testing.T) {
+		f := fieldEncoder{logfmt: true}
+		f.addJSON("req", []byte(`{"id":7,"path":"/a b","tags":["x"]}`))
+		f.addString("my key", "a=b")
+		assert.Equal(t, `req.id=7 req.path="/a b" req.tags="[\"x\"]" my_key="a=b"`, string(f.buf))
+	})
+}
+
+func TestSlogEncoderHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := newSlogEncoderHandler(&buf, Encoder{TimeFormat: "2006"}, slog.LevelInfo)
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+
+	at := time.Date(2023, 9, 22, 13, 38, 39, 0, time.UTC)
+	h = h.WithAttrs([]slog.Attr{slog.String("app", "kpm")}).WithGroup("req")
+	// the group without attributes is omitted just like slog.JSONHandler
+	require.NoError(t, h.Handle(context.Background(), slog.NewRecord(at, slog.LevelInfo, "no attrs", 0)))
+	r := slog.NewRecord(at, slog.LevelInfo, "with attrs", 0)
+	r.AddAttrs(slog.Int("id", 7), slog.Duration("dur", time.Second), slog.Any("err", errors.New("oops")))
+	require.NoError(t, h.Handle(context.Background(), r))
+
+	assert.Equal(t, `{"time":"2023","level":"INFO","msg":"no attrs","app":"kpm"}`+"\n"+
+		`{"time":"2023","level":"INFO","msg":"with attrs","app":"kpm","req":{"id":7,"dur":"1s","err":"oops"}}`+"\n", buf.String())
+
+	t.Run("Should keep the user attributes named as caller", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := newSlogEncoderHandler(&buf, Encoder{Format: ConsoleFormat, TimeFormat: "2006"}, slog.LevelInfo)
+		r := slog.NewRecord(at, slog.LevelInfo, "msg", 0)
+		r.AddAttrs(slog.String("caller", "+62812"), slogMetaAttr("caller", "app/main.go:21"))
+		require.NoError(t, h.Handle(context.Background(), r))
+		assert.Equal(t, "2023\tINFO\tapp/main.go:21\tmsg\t"+`{"caller":"+62812"}`+"\n", buf.String())
+	})
+}
+
+func TestEncoder_Backend(t *testing.T) {
+	year := strconv.Itoa(time.Now().Year())
+
+	testCases := []struct {
+		name   string
+		enc    Encoder
+		expect string
+	}{
+		{
+			name: "JSON",
+			enc:  Encoder{TimeKey: "ts", LevelKey: "lvl", MessageKey: "message", TimeFormat: "2006", LowercaseLevel: true},
+			expect: `{"ts":"` + year + `","lvl":"info","caller":"log/caller_test.go:13","function":"github.com/mdanialr/api-pkg-go/log.logAt","message":"info log","app":"kpm","req":{"id":7,"error":"oops","dur":"1s"}}` + "\n" +
+				`{"ts":"` + year + `","lvl":"error","caller":"log/caller_test.go:13","function":"github.com/mdanialr/api-pkg-go/log.logAt","message":"error log","app":"kpm","req":{"id":7,"error":"oops","dur":"1s"}}` + "\n",
+		},
+		{
+			name: "Logfmt",
+			enc:  Encoder{Format: LogfmtFormat, TimeFormat: "2006"},
+			expect: `time=` + year + ` level=INFO caller=log/caller_test.go:13 function=github.com/mdanialr/api-pkg-go/log.logAt msg="info log" app=kpm req.id=7 req.error=oops req.dur=1s` + "\n" +
+				`time=` + year + ` level=ERROR caller=log/caller_test.go:13 function=github.com/mdanialr/api-pkg-go/log.logAt msg="error log" app=kpm req.id=7 req.error=oops req.dur=1s` + "\n",
+		},
+		{
+			name: "Console",
+			enc:  Encoder{Format: ConsoleFormat, TimeFormat: "2006"},
+			expect: year + "\tINFO\tlog/caller_test.go:13\tgithub.com/mdanialr/api-pkg-go/log.logAt\tinfo log\t" + `{"app":"kpm","req":{"id":7,"error":"oops","dur":"1s"}}` + "\n" +
+				year + "\tERROR\tlog/caller_test.go:13\tgithub.com/mdanialr/api-pkg-go/log.logAt\terror log\t" + `{"app":"kpm","req":{"id":7,"error":"oops","dur":"1s"}}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zw, sw := &bufferWriter{out: FILE}, &bufferWriter{out: FILE}
+			zl := NewZapLoggerWithOptions([]Writer{zw}, WithEncoder(tc.enc), WithCaller())
+			sl := NewSlogLoggerWithOptions([]Writer{sw}, WithEncoder(tc.enc, sw), WithCaller())
+			for _, wr := range []Logger{zl, sl} {
+				wr.Init(time.Second)
+				wr = wr.With(String("app", "kpm")).Group("req", Num("id", 7), Error(errors.New("oops")), Duration("dur", time.Second))
+				logAt(wr)
+			}
+
+			assert.Equal(t, tc.expect, zw.String())
+			assert.Equal(t, tc.expect, sw.String())
+		})
+	}
+}
+
+func TestEncoderFor(t *testing.T) {
+	cns := NewConsoleWriter(DebugLevel)
+	fl := &bufferWriter{out: FILE}
+	obs, _ := NewObserverWriter(DebugLevel, CONSOLE)
+
+	t.Run("Should use the default", func(t *testing.T) {
+		o := newOptions()
+		assert.Equal(t, &defaultConsoleEncoder, o.encoderFor(cns))
+		assert.Nil(t, o.encoderFor(fl))
+		assert.Equal(t, &Encoder{}, o.encoderFor(obs), "observer should always use JSON")
+	})
+	t.Run("Should use the last matching option", func(t *testing.T) {
+		o := newOptions(
+			WithEncoder(Encoder{Format: LogfmtFormat}),
+			WithEncoder(Encoder{Format: ConsoleFormat}, fl),
+		)
+		assert.Equal(t, &Encoder{Format: LogfmtFormat}, o.encoderFor(cns))
+		assert.Equal(t, &Encoder{Format: ConsoleFormat}, o.encoderFor(fl))
+		assert.Equal(t, &Encoder{Format: LogfmtFormat}, o.encoderFor(obs))
+	})
+	t.Run("Should always use JSON for platform and syslog", func(t *testing.T) {
+		pw := NewPlatformWriter(DebugLevel, nil)
+		sw := NewAsyncWriter(NewSyslogWriter(DebugLevel, nil))
+		o := newOptions(WithEncoder(Encoder{Format: LogfmtFormat}), WithEncoder(Encoder{Format: ConsoleFormat}, pw, sw))
+		assert.Nil(t, o.encoderFor(pw))
+		assert.Nil(t, o.encoderFor(sw))
+	})
+}
@@ -0,0 +1,310 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	// errFallbackTimeout returned when the primary Writer does not finish
+	// writing within the timeout.
+	errFallbackTimeout = errors.New("fallback: primary writer timed out")
+	// errFallbackBusy returned when too many writes to the primary Writer are
+	// still pending.
+	errFallbackBusy = errors.New("fallback: primary writer is busy")
+)
+
+// fallbackQueueSize the maximum number of pending writes to the primary
+// Writer when the timeout is set.
+const fallbackQueueSize = 64
+
+// FallbackOpt an option signature for FallbackWriter.
+type FallbackOpt func(*FallbackWriter)
+
+// WithFallbackTimeout treat the write to the primary Writer that does not
+// finish within given dur as failed. The writes are then run by a single
+// background worker with a bounded queue, and the write is also treated as
+// failed when the queue is full. Only use it for the Writer that may block
+// such as network. Default to no timeout.
+func WithFallbackTimeout(dur time.Duration) FallbackOpt {
+	return func(f *FallbackWriter) {
+		f.timeout = dur
+	}
+}
+
+// WithFallbackRetry set how long to keep writing to the secondary Writer
+// after the primary failed, before trying the primary again. Default to 10
+// seconds.
+func WithFallbackRetry(dur time.Duration) FallbackOpt {
+	return func(f *FallbackWriter) {
+		f.retry = dur
+	}
+}
+
+// WithFallbackOnError register given fn that is called with the primary
+// Writer whenever it failed, since the error is not returned as long as the
+// secondary Writer succeed.
+func WithFallbackOnError(fn WriteErrorHandler) FallbackOpt {
+	return func(f *FallbackWriter) {
+		f.onErr = append(f.onErr, fn)
+	}
+}
+
+// NewFallbackWriter return FallbackWriter that write logs to given primary,
+// and switch to given secondary whenever the primary return error, time out
+// or report a new delivery error through HealthReporter. The primary is tried
+// again periodically and used again once it recovers. The log entries dropped
+// by the batch Writer as the primary are written to the secondary instead.
+// Example NEWRELIC or HTTP Writer that falls back to FILE Writer.
+func NewFallbackWriter(primary, secondary Writer, opts ...FallbackOpt) *FallbackWriter {
+	f := &FallbackWriter{primary: primary, secondary: secondary, retry: 10 * time.Second, now: time.Now}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.health = healthOf(primary)
+	if dr := dropReporterOf(primary); dr != nil {
+		dr.addDropHook(f, f.reroute)
+	}
+	if f.timeout > 0 {
+		f.queue = make(chan fallbackWrite, fallbackQueueSize)
+		f.stop = make(chan struct{})
+		go f.run()
+	}
+	return f
+}
+
+// fallbackWrite the write that is queued to the primary Writer.
+type fallbackWrite struct {
+	p        []byte
+	deadline time.Time
+	done     chan error
+}
+
+// FallbackWriter Writer implementer that write logs to the secondary Writer
+// while the primary one is failing. It use the Output and Level of the
+// primary Writer, so the log entries are written to the secondary regardless
+// of its own Level.
+type FallbackWriter struct {
+	primary   Writer
+	secondary Writer
+	timeout   time.Duration
+	retry     time.Duration
+	onErr     []WriteErrorHandler
+	now       func() time.Time
+	health    HealthReporter
+
+	queue    chan fallbackWrite
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu       sync.Mutex
+	failedAt time.Time
+	// seenErrAt when the last delivery error reported by health happened,
+	// so the same error is not handled twice.
+	seenErrAt time.Time
+	// hooks the drop callbacks by their owner.
+	hooks map[any]func(lines [][]byte, err error)
+}
+
+// Write implement io.Writer. Return error only if both the primary and the
+// secondary Writer failed.
+func (f *FallbackWriter) Write(p []byte) (int, error) {
+	var perr error
+	if f.usePrimary() {
+		if perr = f.primaryErr(); perr == nil {
+			if perr = f.writePrimary(p); perr == nil {
+				// the primary that deliver in background only queued it, so
+				// it's healthy as long as no new delivery error is reported
+				if f.health == nil {
+					f.setFailed(time.Time{})
+				}
+				return len(p), nil
+			}
+		}
+		f.setFailed(f.now())
+		for _, fn := range f.onErr {
+			fn(f.primary, perr)
+		}
+	}
+	if _, err := f.secondary.Writer().Write(p); err != nil {
+		return 0, errors.Join(perr, err)
+	}
+	return len(p), nil
+}
+
+// usePrimary return true if the primary Writer is healthy or it's time to try
+// it again.
+func (f *FallbackWriter) usePrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedAt.IsZero() || f.now().Sub(f.failedAt) >= f.retry
+}
+
+// primaryErr return the new delivery error reported by the primary Writer if
+// it implements HealthReporter.
+func (f *FallbackWriter) primaryErr() error {
+	if f.health == nil {
+		return nil
+	}
+	at, err := f.health.LastError()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil || !at.After(f.seenErrAt) {
+		return nil
+	}
+	f.seenErrAt = at
+	return err
+}
+
+// setFailed set when the primary Writer failed, or zero if it's healthy.
+func (f *FallbackWriter) setFailed(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failedAt = t
+}
+
+// writePrimary write given p to the primary Writer within the timeout if any.
+func (f *FallbackWriter) writePrimary(p []byte) error {
+	if f.timeout <= 0 {
+		_, err := f.primary.Writer().Write(p)
+		return err
+	}
+
+	// copy it, since the primary may still use it after timed out
+	w := fallbackWrite{
+		p:        append([]byte(nil), p...),
+		deadline: time.Now().Add(f.timeout),
+		done:     make(chan error, 1),
+	}
+	select {
+	case f.queue <- w:
+	default:
+		return errFallbackBusy
+	}
+	t := time.NewTimer(f.timeout)
+	defer t.Stop()
+	select {
+	case err := <-w.done:
+		return err
+	case <-t.C:
+		return errFallbackTimeout
+	}
+}
+
+// run the background worker that write the queued writes to the primary
+// Writer until stopped. The write that already timed out is skipped, since
+// it's already written to the secondary Writer.
+func (f *FallbackWriter) run() {
+	out := f.primary.Writer()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case w := <-f.queue:
+			if time.Now().After(w.deadline) {
+				w.done <- errFallbackTimeout
+				continue
+			}
+			_, err := out.Write(w.p)
+			w.done <- err
+		}
+	}
+}
+
+// reroute write given lines dropped by the primary Writer to the secondary
+// Writer, and report the ones that still failed to the drop callbacks.
+func (f *FallbackWriter) reroute(lines [][]byte, err error) {
+	var (
+		failed [][]byte
+		last   error
+	)
+	for _, l := range lines {
+		// copy it, so the newline never overwrite the dropped line
+		if _, werr := f.secondary.Writer().Write(append(l[:len(l):len(l)], '\n')); werr != nil {
+			failed, last = append(failed, l), werr
+		}
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	hooks := make([]func(lines [][]byte, err error), 0, len(f.hooks))
+	for _, fn := range f.hooks {
+		hooks = append(hooks, fn)
+	}
+	f.mu.Unlock()
+	for _, fn := range hooks {
+		fn(failed, errors.Join(err, last))
+	}
+}
+
+// addDropHook implement dropReporter, so only the log lines that could not
+// be written to the secondary Writer either are reported.
+func (f *FallbackWriter) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.hooks == nil {
+		f.hooks = make(map[any]func(lines [][]byte, err error))
+	}
+	f.hooks[key] = fn
+}
+
+// healthOf return the HealthReporter of given w by unwrapping it if needed, or
+// nil if there is none.
+func healthOf(w Writer) HealthReporter {
+	for w != nil {
+		if hr, ok := w.(HealthReporter); ok {
+			return hr
+		}
+		u, ok := w.(interface{ Unwrap() Writer })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+	return nil
+}
+
+// Active return the Writer that is currently written to.
+func (f *FallbackWriter) Active() Writer {
+	if f.usePrimary() {
+		return f.primary
+	}
+	return f.secondary
+}
+
+// Unwrap return the primary Writer.
+func (f *FallbackWriter) Unwrap() Writer { return f.primary }
+
+func (f *FallbackWriter) Writer() io.Writer { return f }
+func (f *FallbackWriter) Output() Output    { return f.primary.Output() }
+func (f *FallbackWriter) Level() Level      { return f.primary.Level() }
+
+// SetLevel change the Level of both the primary and the secondary Writer if
+// they implement LevelSetter.
+func (f *FallbackWriter) SetLevel(lvl Level) {
+	for _, w := range []Writer{f.primary, f.secondary} {
+		if ls, ok := w.(LevelSetter); ok {
+			ls.SetLevel(lvl)
+		}
+	}
+}
+
+// Wait wait for both the primary and the secondary Writer.
+func (f *FallbackWriter) Wait(dur time.Duration) {
+	f.primary.Wait(dur)
+	f.secondary.Wait(dur)
+}
+
+// Flush stop the background worker if any, then flush both the primary and
+// the secondary Writer.
+func (f *FallbackWriter) Flush(dur time.Duration) {
+	if f.stop != nil {
+		f.stopOnce.Do(func() { close(f.stop) })
+	}
+	f.primary.Flush(dur)
+	f.secondary.Flush(dur)
+}
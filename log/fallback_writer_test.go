@@ -0,0 +1,266 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBrokenWriter = errors.New("broken writer")
+
+// brokenWriter Writer implementer that fail or block on write when told so.
+type brokenWriter struct {
+	bufferWriter
+	broken atomic.Bool
+	block  chan struct{}
+}
+
+func (b *brokenWriter) Writer() io.Writer { return b }
+func (b *brokenWriter) Write(p []byte) (int, error) {
+	if b.block != nil {
+		<-b.block
+	}
+	if b.broken.Load() {
+		return 0, errBrokenWriter
+	}
+	return b.bufferWriter.Write(p)
+}
+
+// unhealthyWriter Writer implementer that always succeed to write but report
+// the delivery error when told so.
+type unhealthyWriter struct {
+	bufferWriter
+	mu       sync.Mutex
+	failedAt time.Time
+	err      error
+}
+
+func (u *unhealthyWriter) fail(at time.Time, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.failedAt, u.err = at, err
+}
+func (u *unhealthyWriter) LastError() (time.Time, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.failedAt, u.err
+}
+
+func TestFallbackWriter_Write(t *testing.T) {
+	t.Run("Should fall back to secondary and switch back once primary recovers", func(t *testing.T) {
+		primary, secondary := &brokenWriter{}, &bufferWriter{}
+		now := time.Now()
+		var failed []error
+		f := NewFallbackWriter(primary, secondary,
+			WithFallbackRetry(time.Minute),
+			WithFallbackOnError(func(w Writer, err error) {
+				assert.Equal(t, primary, w)
+				failed = append(failed, err)
+			}),
+		)
+		f.now = func() time.Time { return now }
+
+		_, err := f.Write([]byte("1\n"))
+		require.NoError(t, err)
+		assert.Equal(t, primary, f.Active())
+
+		primary.broken.Store(true)
+		_, err = f.Write([]byte("2\n"))
+		require.NoError(t, err)
+		assert.Equal(t, secondary, f.Active())
+
+		// primary is not tried until the retry is over
+		primary.broken.Store(false)
+		_, err = f.Write([]byte("3\n"))
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		_, err = f.Write([]byte("4\n"))
+		require.NoError(t, err)
+		assert.Equal(t, primary, f.Active())
+
+		assert.Equal(t, "1\n4\n", primary.String())
+		assert.Equal(t, "2\n3\n", secondary.String())
+		assert.Equal(t, []error{errBrokenWriter}, failed)
+	})
+	t.Run("Should fall back to secondary when primary timed out", func(t *testing.T) {
+		primary, secondary := &brokenWriter{block: make(chan struct{})}, &bufferWriter{}
+		defer close(primary.block)
+		f := NewFallbackWriter(primary, secondary, WithFallbackTimeout(time.Millisecond))
+
+		_, err := f.Write([]byte("1\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "1\n", secondary.String())
+		assert.Equal(t, secondary, f.Active())
+	})
+	t.Run("Should bound the pending writes to the primary", func(t *testing.T) {
+		primary, secondary := &brokenWriter{block: make(chan struct{})}, &bufferWriter{}
+		var mu sync.Mutex
+		var failed []error
+		f := NewFallbackWriter(primary, secondary,
+			WithFallbackTimeout(time.Millisecond),
+			WithFallbackRetry(0),
+			WithFallbackOnError(func(_ Writer, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, err)
+			}),
+		)
+
+		// the first one is blocking the worker, the rest fill the queue
+		for i := 0; i < fallbackQueueSize+2; i++ {
+			_, err := f.Write([]byte("1\n"))
+			require.NoError(t, err)
+		}
+		mu.Lock()
+		assert.Len(t, failed, fallbackQueueSize+2)
+		assert.ErrorIs(t, failed[0], errFallbackTimeout)
+		assert.ErrorIs(t, failed[len(failed)-1], errFallbackBusy)
+		mu.Unlock()
+		assert.Equal(t, strings.Repeat("1\n", fallbackQueueSize+2), secondary.String())
+
+		// the timed out writes are not written again to the primary
+		close(primary.block)
+		assert.Eventually(t, func() bool { return len(f.queue) == 0 }, time.Second, time.Millisecond)
+		f.Flush(time.Millisecond)
+		assert.Equal(t, "1\n", primary.String())
+	})
+	t.Run("Should fall back to secondary when primary report delivery error", func(t *testing.T) {
+		primary, secondary := &unhealthyWriter{}, &bufferWriter{}
+		now := time.Now()
+		var failed []error
+		f := NewFallbackWriter(NewAsyncWriter(primary), secondary,
+			WithFallbackRetry(time.Minute),
+			WithFallbackOnError(func(_ Writer, err error) { failed = append(failed, err) }),
+		)
+		f.now = func() time.Time { return now }
+
+		primary.fail(now, errBrokenWriter)
+		_, err := f.Write([]byte("1\n"))
+		require.NoError(t, err)
+		assert.Equal(t, secondary, f.Active())
+
+		// the same delivery error is not handled again after the retry
+		now = now.Add(time.Minute)
+		_, err = f.Write([]byte("2\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "1\n", secondary.String())
+
+		primary.fail(now, errBrokenWriter)
+		_, err = f.Write([]byte("3\n"))
+		require.NoError(t, err)
+		f.Flush(time.Second)
+		assert.Equal(t, "2\n", primary.String())
+		assert.Equal(t, "1\n3\n", secondary.String())
+		assert.Equal(t, []error{errBrokenWriter, errBrokenWriter}, failed)
+	})
+	t.Run("Should write the lines dropped by the batch primary to secondary", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+		primary := NewHTTPWriter(InfoLevel, NewConfig(WithHTTPURL(srv.URL), WithHTTPBatch(BatchConfig{MaxRetry: -1})))
+		secondary := &brokenWriter{}
+		f := NewFallbackWriter(primary, secondary)
+		var reported []string
+		f.addDropHook("test", func(lines [][]byte, err error) {
+			reported = append(reported, string(lines[0]))
+			assert.ErrorIs(t, err, errBrokenWriter)
+		})
+
+		_, err := f.Write([]byte(`{"msg":"1"}` + "\n"))
+		require.NoError(t, err)
+		assert.Equal(t, primary, f.Active())
+		f.Flush(time.Second)
+		assert.Equal(t, `{"msg":"1"}`+"\n", secondary.String())
+		assert.Empty(t, reported)
+
+		// only report the ones that failed to be written to secondary too,
+		// such as the one written to the primary after flushed
+		secondary.broken.Store(true)
+		primary.Writer().Write([]byte(`{"msg":"2"}` + "\n"))
+		assert.Equal(t, []string{`{"msg":"2"}`}, reported)
+	})
+	t.Run("Should return error if both failed", func(t *testing.T) {
+		primary, secondary := &brokenWriter{}, &brokenWriter{}
+		primary.broken.Store(true)
+		secondary.broken.Store(true)
+		f := NewFallbackWriter(primary, secondary)
+
+		_, err := f.Write([]byte("1\n"))
+		assert.ErrorIs(t, err, errBrokenWriter)
+	})
+}
+
+func TestFallbackWriter_SetLevel(t *testing.T) {
+	primary, _ := NewObserverWriter(WarnLevel, NEWRELIC)
+	secondary, _ := NewObserverWriter(InfoLevel, FILE)
+	f := NewFallbackWriter(primary, secondary)
+	assert.Equal(t, NEWRELIC, f.Output())
+	assert.Equal(t, WarnLevel, f.Level())
+
+	f.SetLevel(ErrorLevel)
+	assert.Equal(t, ErrorLevel, primary.Level())
+	assert.Equal(t, ErrorLevel, secondary.Level())
+}
+
+func TestLoggerWithOnWriteError(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			broken, healthy := &brokenWriter{bufferWriter: bufferWriter{out: FILE}}, &bufferWriter{out: FILE}
+			broken.broken.Store(true)
+			var reported []Writer
+			wr := fn([]Writer{broken, healthy}, WithOnWriteError(func(w Writer, err error) {
+				assert.ErrorIs(t, err, errBrokenWriter)
+				reported = append(reported, w)
+			}))
+			wr.Init(time.Microsecond)
+
+			wr.Inf("first")
+			wr.With(String("k", "v")).Inf("second")
+			broken.broken.Store(false)
+			wr.Inf("third")
+
+			assert.Equal(t, []Writer{broken, broken}, reported)
+			assert.Equal(t, uint64(2), WriteErrors(wr, broken))
+			assert.Equal(t, uint64(0), WriteErrors(wr, healthy))
+			assert.Equal(t, uint64(0), WriteErrors(NewNop(), broken))
+			assert.Contains(t, broken.String(), "third")
+		})
+		t.Run(name+" should report the dropped lines of batch writer", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer srv.Close()
+			hw := NewHTTPWriter(InfoLevel, NewConfig(WithHTTPURL(srv.URL)))
+			var reported atomic.Int32
+			wr := fn([]Writer{hw}, WithOnWriteError(func(w Writer, err error) {
+				assert.Equal(t, hw, w)
+				assert.Error(t, err)
+				reported.Add(1)
+			}))
+			// the hook of the previous Init is replaced
+			wr.Init(time.Microsecond)
+			wr.Init(time.Microsecond)
+
+			wr.Inf("first")
+			wr.Inf("second")
+			wr.Flush(time.Second)
+			assert.Equal(t, int32(1), reported.Load())
+			assert.Equal(t, uint64(2), WriteErrors(wr, hw))
+		})
+	}
+}
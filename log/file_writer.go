@@ -1,12 +1,51 @@
 package log
 
 import (
+	"compress/gzip"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// errSymlinkRegularFile returned when the symlink could not be created since
+// there is already a regular file at the path.
+var errSymlinkRegularFile = errors.New("file: regular file exists at the symlink path")
+
+// FileRotation define the time based rotation of the file Writer.
+type FileRotation int8
+
+const (
+	FileRotateNone   FileRotation = iota // FileRotateNone only rotate by size
+	FileRotateHourly                     // FileRotateHourly start new file every hour
+	FileRotateDaily                      // FileRotateDaily start new file every day
+)
+
+// layout return the time layout used to stamp the file name.
+func (r FileRotation) layout() string {
+	if r == FileRotateHourly {
+		return "2006-01-02T15"
+	}
+	return "2006-01-02"
+}
+
+// period return the start of the rotation period of given t.
+func (r FileRotation) period(t time.Time) time.Time {
+	if r == FileRotateHourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 // NewFileWriter return Writer implementer that write logs to designated file
 // based on the given Config.File and set given lvl as the log Level.
 func NewFileWriter(lvl Level, cnf *Config) Writer {
@@ -14,19 +53,235 @@ func NewFileWriter(lvl Level, cnf *Config) Writer {
 		cnf = &Config{}
 	}
 
-	return &fileOutputWithLumberjack{lvl: lvl, wr: setupLumberjack(&cnf.File)}
+	lj := setupLumberjack(&cnf.File)
+	f := &fileOutputWithLumberjack{lvl: NewAtomicLevel(lvl), cnf: cnf.File, now: time.Now}
+	// keep the default value
+	f.cnf.Path, f.cnf.Size, f.cnf.Age, f.cnf.Num = lj.Filename, lj.MaxSize, lj.MaxAge, lj.MaxBackups
+	// opened on the first write
+	f.wr = lj
+	if f.cnf.Rotate != FileRotateNone {
+		// the same lumberjack.Logger is reused for every period since it
+		// never stop its own background goroutine. The previous files are
+		// compressed and cleaned up by mill instead, so lumberjack never
+		// touch the files while the Filename is changed.
+		lj.MaxAge, lj.MaxBackups, lj.Compress = 0, 0, false
+	}
+	if f.cnf.Reopen {
+		f.sig, f.stop = make(chan os.Signal, 1), make(chan struct{})
+		signal.Notify(f.sig, syscall.SIGHUP)
+		go f.listen()
+	}
+	return f
 }
 
 type fileOutputWithLumberjack struct {
-	wr  *lumberjack.Logger
-	lvl Level
+	lvl *AtomicLevel
+	cnf FileConfig
+	now func() time.Time
+
+	mu     sync.Mutex
+	wr     *lumberjack.Logger
+	period time.Time
+
+	// milling hold the running compression and cleanup of the previous files,
+	// which run one at a time.
+	milling sync.WaitGroup
+	millMu  sync.Mutex
+	sig     chan os.Signal
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// Write implement io.Writer. Start new date-stamped file first if the
+// rotation period is over. The log is still written when the symlink could not
+// be updated, but the error is returned.
+func (f *fileOutputWithLumberjack) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var rerr error
+	if f.cnf.Rotate != FileRotateNone {
+		t := f.now()
+		if f.cnf.UTC {
+			t = t.UTC()
+		}
+		if pr := f.cnf.Rotate.period(t); !pr.Equal(f.period) {
+			rerr = f.rotate(pr)
+		}
+	}
+	n, err := f.wr.Write(p)
+	if err == nil {
+		err = rerr
+	}
+	return n, err
+}
+
+// rotate close the current file and start the date-stamped file of given
+// period, then compress and clean up the previous files in background. Must be
+// called while holding the lock.
+func (f *fileOutputWithLumberjack) rotate(period time.Time) error {
+	f.wr.Close()
+	f.wr.Filename, f.period = f.stamped(period), period
+
+	var err error
+	if f.cnf.Symlink {
+		err = f.symlink(f.wr.Filename)
+	}
+	f.milling.Add(1)
+	go func() {
+		defer f.milling.Done()
+		f.mill(period)
+	}()
+	return err
+}
+
+// stamped return the file name of given period such as 'app-2006-01-02.log'.
+func (f *fileOutputWithLumberjack) stamped(period time.Time) string {
+	ext := filepath.Ext(f.cnf.Path)
+	return strings.TrimSuffix(f.cnf.Path, ext) + "-" + period.Format(f.cnf.Rotate.layout()) + ext
+}
+
+// symlink point the symlink at Path to given target. The existing regular
+// file at Path is never replaced, errSymlinkRegularFile is returned instead.
+func (f *fileOutputWithLumberjack) symlink(target string) error {
+	if fi, err := os.Lstat(f.cnf.Path); err == nil && fi.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%w: %s", errSymlinkRegularFile, f.cnf.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.cnf.Path), 0o755); err != nil {
+		return err
+	}
+	tmp := f.cnf.Path + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(target), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.cnf.Path)
+}
+
+// mill compress the date-stamped files before given period and their size
+// based backups if enabled, then remove the previous files that exceed Num or
+// older than Age days. The file of given period and the later ones are left
+// untouched.
+func (f *fileOutputWithLumberjack) mill(period time.Time) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	dir, ext := filepath.Dir(f.cnf.Path), filepath.Ext(f.cnf.Path)
+	prefix := strings.TrimSuffix(filepath.Base(f.cnf.Path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var old []os.FileInfo
+	for _, e := range entries {
+		name := e.Name()
+		plain := strings.TrimSuffix(name, ".gz")
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(plain, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if t, err := time.ParseInLocation(f.cnf.Rotate.layout(), stamp, period.Location()); err == nil && !t.Before(period) {
+			continue
+		}
+		// the size based backups are never written again, so it's safe to
+		// compress them along with the previous date-stamped files
+		if f.cnf.Compress && name == plain && gzipFile(filepath.Join(dir, name)) == nil {
+			name += ".gz"
+		}
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		old = append(old, fi)
+	}
+
+	sort.Slice(old, func(i, j int) bool { return old[i].ModTime().After(old[j].ModTime()) })
+	cutoff := time.Now().Add(-time.Duration(f.cnf.Age) * 24 * time.Hour)
+	for i, fi := range old {
+		if i >= f.cnf.Num || fi.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dir, fi.Name()))
+		}
+	}
+}
+
+// gzipFile compress given file to '.gz' that keep its modification time, then
+// remove the original one.
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	os.Chtimes(tmp, fi.ModTime(), fi.ModTime())
+	if err = os.Rename(tmp, name+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// listen reopen the file on every SIGHUP until stopped.
+func (f *fileOutputWithLumberjack) listen() {
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-f.sig:
+			f.reopen()
+		}
+	}
 }
 
-func (f *fileOutputWithLumberjack) Writer() io.Writer     { return f.wr }
-func (f *fileOutputWithLumberjack) Output() Output        { return FILE }
-func (f *fileOutputWithLumberjack) Level() Level          { return f.lvl }
-func (f *fileOutputWithLumberjack) Wait(_ time.Duration)  {}
-func (f *fileOutputWithLumberjack) Flush(_ time.Duration) { f.wr.Close() }
+// reopen close the current file, so the file at the same path is opened or
+// created on the next write.
+func (f *fileOutputWithLumberjack) reopen() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.wr != nil {
+		f.wr.Close()
+	}
+}
+
+func (f *fileOutputWithLumberjack) Writer() io.Writer    { return f }
+func (f *fileOutputWithLumberjack) Output() Output       { return FILE }
+func (f *fileOutputWithLumberjack) Level() Level         { return f.lvl.Level() }
+func (f *fileOutputWithLumberjack) SetLevel(lvl Level)   { f.lvl.SetLevel(lvl) }
+func (f *fileOutputWithLumberjack) Wait(_ time.Duration) {}
+
+// Flush stop listening to SIGHUP, close the current file and wait for the
+// previous files to be compressed.
+func (f *fileOutputWithLumberjack) Flush(_ time.Duration) {
+	f.once.Do(func() {
+		if f.sig != nil {
+			signal.Stop(f.sig)
+			close(f.stop)
+		}
+	})
+	f.reopen()
+	f.milling.Wait()
+}
 
 // setupLumberjack init and set default value to lumberjack.Logger if no value
 // provided in given config.
@@ -36,7 +291,8 @@ func setupLumberjack(cnf *FileConfig) *lumberjack.Logger {
 		MaxSize:    cnf.Size,
 		MaxAge:     cnf.Age,
 		MaxBackups: cnf.Num,
-		LocalTime:  true,
+		LocalTime:  !cnf.UTC,
+		Compress:   cnf.Compress,
 	}
 
 	// set default value
@@ -1,9 +1,16 @@
 package log
 
 import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -14,26 +21,33 @@ func TestSetupLumberjack(t *testing.T) {
 		assert.Equal(t, 150, ll.MaxSize)
 		assert.Equal(t, 28, ll.MaxAge)
 		assert.Equal(t, 7, ll.MaxBackups)
+		assert.True(t, ll.LocalTime)
+		assert.False(t, ll.Compress)
 	})
 	t.Run("Given config with certain value should use that value instead", func(t *testing.T) {
 		fc := &FileConfig{
-			Path: "./app-logs/app.log",
-			Size: 200,
-			Age:  25,
-			Num:  10,
+			Path:     "./app-logs/app.log",
+			Size:     200,
+			Age:      25,
+			Num:      10,
+			UTC:      true,
+			Compress: true,
 		}
 		ll := setupLumberjack(fc)
 		assert.Equal(t, "./app-logs/app.log", ll.Filename)
 		assert.Equal(t, 200, ll.MaxSize)
 		assert.Equal(t, 25, ll.MaxAge)
 		assert.Equal(t, 10, ll.MaxBackups)
+		assert.False(t, ll.LocalTime)
+		assert.True(t, ll.Compress)
 	})
 }
 
 func TestNewFileWriter(t *testing.T) {
 	t.Run("Should return the expected value in each Writer implementation", func(t *testing.T) {
 		wr := NewFileWriter(ErrorLevel, nil)
-		assert.IsType(t, &lumberjack.Logger{}, wr.Writer())
+		assert.IsType(t, &fileOutputWithLumberjack{}, wr.Writer())
+		assert.IsType(t, &lumberjack.Logger{}, wr.(*fileOutputWithLumberjack).wr)
 		assert.Equal(t, FILE, wr.Output())
 		assert.Equal(t, ErrorLevel, wr.Level())
 
@@ -42,3 +56,129 @@ func TestNewFileWriter(t *testing.T) {
 		wr.Flush(-1)
 	})
 }
+
+func TestFileWriter_Rotate(t *testing.T) {
+	t.Run("Daily rotation should compress the previous file and keep the symlink", func(t *testing.T) {
+		dir := t.TempDir()
+		cnf := NewConfig(
+			WithFilePath(filepath.Join(dir, "app.log")),
+			WithFileRotate(FileRotateDaily),
+			WithFileCompress(true),
+			WithFileUTC(true),
+			WithFileSymlink(true),
+		)
+		wr := NewFileWriter(DebugLevel, cnf)
+		f := wr.(*fileOutputWithLumberjack)
+		now := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+		f.now = func() time.Time { return now }
+
+		wr.Writer().Write([]byte("first\n"))
+		target, err := os.Readlink(filepath.Join(dir, "app.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "app-2026-10-17.log", target)
+
+		f.milling.Wait()
+		lj := f.wr
+		// simulate the size based backup of the previous period
+		backup := filepath.Join(dir, "app-2026-10-17-2026-10-17T23-59-30.000.log")
+		require.NoError(t, os.WriteFile(backup, []byte("backup\n"), 0o644))
+		now = now.Add(time.Minute)
+		wr.Writer().Write([]byte("second\n"))
+		wr.Flush(time.Second)
+		// the same lumberjack.Logger is reused
+		assert.Same(t, lj, f.wr)
+		assert.NoFileExists(t, backup)
+		assert.FileExists(t, backup+".gz")
+
+		target, err = os.Readlink(filepath.Join(dir, "app.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "app-2026-10-18.log", target)
+		b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "second\n", string(b))
+
+		assert.NoFileExists(t, filepath.Join(dir, "app-2026-10-17.log"))
+		gf, err := os.Open(filepath.Join(dir, "app-2026-10-17.log.gz"))
+		require.NoError(t, err)
+		defer gf.Close()
+		gz, err := gzip.NewReader(gf)
+		require.NoError(t, err)
+		b, err = io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, "first\n", string(b))
+	})
+	t.Run("Hourly rotation should remove the files that exceed the maximum backup", func(t *testing.T) {
+		dir := t.TempDir()
+		cnf := NewConfig(
+			WithFilePath(filepath.Join(dir, "app.log")),
+			WithFileRotate(FileRotateHourly),
+			WithFileMaxBackup(1),
+		)
+		wr := NewFileWriter(DebugLevel, cnf)
+		f := wr.(*fileOutputWithLumberjack)
+		now := time.Date(2026, 10, 18, 10, 30, 0, 0, time.Local)
+		f.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			wr.Writer().Write([]byte("hello\n"))
+			// make sure the older file has older modification time
+			name := filepath.Join(dir, "app-"+now.Format("2006-01-02T15")+".log")
+			mt := time.Now().Add(time.Duration(i) * time.Minute)
+			require.NoError(t, os.Chtimes(name, mt, mt))
+			f.milling.Wait()
+			now = now.Add(time.Hour)
+		}
+		wr.Flush(time.Second)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.Equal(t, []string{"app-2026-10-18T11.log", "app-2026-10-18T12.log"}, names)
+	})
+	t.Run("Symlink should never replace regular file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app.log"), []byte("old\n"), 0o644))
+
+		cnf := NewConfig(
+			WithFilePath(filepath.Join(dir, "app.log")),
+			WithFileRotate(FileRotateDaily),
+			WithFileSymlink(true),
+		)
+		wr := NewFileWriter(DebugLevel, cnf)
+		_, err := wr.Writer().Write([]byte("new\n"))
+		assert.ErrorIs(t, err, errSymlinkRegularFile)
+		wr.Flush(time.Second)
+
+		b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "old\n", string(b))
+	})
+}
+
+func TestFileWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	cnf := NewConfig(WithFilePath(filepath.Join(dir, "app.log")), WithFileReopen(true))
+	wr := NewFileWriter(DebugLevel, cnf)
+	f := wr.(*fileOutputWithLumberjack)
+
+	wr.Writer().Write([]byte("first\n"))
+	// rotated by external logrotate
+	require.NoError(t, os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")))
+	f.sig <- syscall.SIGHUP
+	require.Eventually(t, func() bool {
+		wr.Writer().Write([]byte("second\n"))
+		_, err := os.Stat(filepath.Join(dir, "app.log"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	wr.Flush(time.Second)
+
+	b, err := os.ReadFile(filepath.Join(dir, "app.log.1"))
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(b[:6]))
+	b, err = os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(b))
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FluentMode define how the logs are sent using the Fluent Forward protocol.
+type FluentMode int8
+
+const (
+	// FluentForward send the batch as an array of entries.
+	FluentForward FluentMode = iota
+	// FluentPackedForward send the batch as a MessagePack binary of
+	// concatenated entries, which is cheaper to be decoded by the server.
+	FluentPackedForward
+)
+
+// NewFluentWriter return Writer implementer that send logs in batch to
+// fluentd or fluent-bit using the Fluent Forward protocol by given
+// Config.Fluent and set given lvl as the log Level. The logs are buffered and
+// the connection is reestablished with backoff while the server restarts.
+func NewFluentWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	f := &fluentOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.Fluent}
+	// set default value
+	if f.cnf.Network == "" {
+		f.cnf.Network = "tcp"
+	}
+	if f.cnf.Addr == "" {
+		f.cnf.Addr = "localhost:24224"
+	}
+	if f.cnf.Tag == "" {
+		f.cnf.Tag = "app"
+	}
+	if f.cnf.Timeout == 0 {
+		f.cnf.Timeout = 10 * time.Second
+	}
+	f.bt = newBatcher(f.cnf.Batch, "fluent-"+shortHash(f.cnf.Addr+f.cnf.Tag), f.send, stampedEntry)
+	return f
+}
+
+type fluentOutput struct {
+	lvl *AtomicLevel
+	cnf FluentConfig
+	bt  *batcher
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// Write implement io.Writer. The entry time is queued along given p, so the
+// entry keep its original time even when it's spilled to disk.
+func (f *fluentOutput) Write(p []byte) (int, error) {
+	ts := strconv.FormatInt(entryTime(p, time.Now()).UnixNano(), 10)
+	f.bt.add(append([]byte(ts+" "), bytes.TrimSpace(p)...))
+	return len(p), nil
+}
+
+// send given lines as a single Forward or PackedForward message then wait for
+// the ack if enabled.
+func (f *fluentOutput) send(ctx context.Context, lines [][]byte) error {
+	var chunk string
+	if f.cnf.Ack {
+		id := make([]byte, 16)
+		rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+	}
+	msg := f.encode(lines, chunk)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.connect(ctx); err != nil {
+		return err
+	}
+	f.conn.SetDeadline(time.Now().Add(f.cnf.Timeout))
+	if _, err := f.conn.Write(msg); err != nil {
+		f.close()
+		return err
+	}
+	if !f.cnf.Ack {
+		return nil
+	}
+
+	res, err := readMsgpackStringMap(f.rd)
+	if err != nil {
+		f.close()
+		return fmt.Errorf("failed to read ack: %w", err)
+	}
+	if res["ack"] != chunk {
+		f.close()
+		return fmt.Errorf("unexpected ack %q, expected %q", res["ack"], chunk)
+	}
+	return nil
+}
+
+// encode given lines as Forward or PackedForward message, with the chunk
+// option if given chunk is not empty.
+func (f *fluentOutput) encode(lines [][]byte, chunk string) []byte {
+	var entries []byte
+	for _, l := range lines {
+		ts, line, _ := bytes.Cut(l, []byte{' '})
+		nsec, _ := strconv.ParseInt(string(ts), 10, 64)
+
+		entries = append(entries, 0x92)
+		entries = appendMsgpackEventTime(entries, time.Unix(0, nsec))
+		entries = appendMsgpack(entries, fluentRecord(line))
+	}
+
+	opts := 0
+	if chunk != "" {
+		opts++
+	}
+	if f.cnf.Mode == FluentPackedForward {
+		opts++
+	}
+
+	msg := []byte{0x93}
+	msg = appendMsgpackString(msg, f.cnf.Tag)
+	if f.cnf.Mode == FluentPackedForward {
+		msg = appendMsgpackBin(msg, entries)
+	} else {
+		msg = appendMsgpackHeader(msg, len(lines), 0x90, 0xdc)
+		msg = append(msg, entries...)
+	}
+	msg = appendMsgpackHeader(msg, opts, 0x80, 0xde)
+	if f.cnf.Mode == FluentPackedForward {
+		msg = appendMsgpackString(msg, "size")
+		msg = appendMsgpackInt(msg, int64(len(lines)))
+	}
+	if chunk != "" {
+		msg = appendMsgpackString(msg, "chunk")
+		msg = appendMsgpackString(msg, chunk)
+	}
+	return msg
+}
+
+// connect dial the server if not connected yet. Must be called while holding
+// the lock.
+func (f *fluentOutput) connect(ctx context.Context) error {
+	if f.conn != nil {
+		return nil
+	}
+	d := &net.Dialer{Timeout: f.cnf.Timeout}
+	conn, err := d.DialContext(ctx, f.cnf.Network, f.cnf.Addr)
+	if err != nil {
+		return err
+	}
+	f.conn, f.rd = conn, bufio.NewReader(conn)
+	return nil
+}
+
+// close the current connection. Must be called while holding the lock.
+func (f *fluentOutput) close() {
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn, f.rd = nil, nil
+	}
+}
+
+func (f *fluentOutput) Writer() io.Writer  { return f }
+func (f *fluentOutput) Output() Output     { return FLUENT }
+func (f *fluentOutput) Level() Level       { return f.lvl.Level() }
+func (f *fluentOutput) SetLevel(lvl Level) { f.lvl.SetLevel(lvl) }
+
+// Wait try to connect to the server within given dur.
+func (f *fluentOutput) Wait(dur time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), dur)
+	defer cancel()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connect(ctx)
+}
+
+func (f *fluentOutput) LastError() (time.Time, error) { return f.bt.lastError() }
+
+// addDropHook implement dropReporter.
+func (f *fluentOutput) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	f.bt.addDropHook(key, fn)
+}
+
+// Flush send every queued logs within given dur then close the connection.
+func (f *fluentOutput) Flush(dur time.Duration) {
+	f.bt.flush(dur)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.close()
+}
+
+// fluentRecord decode given JSON encoded log line as the record, or wrap it
+// as 'message' if it's not a JSON object.
+func fluentRecord(line []byte) map[string]any {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var rec map[string]any
+	if err := dec.Decode(&rec); err != nil || rec == nil {
+		return map[string]any{"message": string(line)}
+	}
+	return rec
+}
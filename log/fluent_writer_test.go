@@ -0,0 +1,159 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fluentServer accept Fluent Forward messages and send them to msgs, then
+// reply the ack if requested.
+func fluentServer(t *testing.T, ln net.Listener, msgs chan<- []any) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				v, err := readMsgpack(r)
+				if err != nil {
+					return
+				}
+				msg := v.([]any)
+				msgs <- msg
+				if chunk, ok := msg[2].(map[string]any)["chunk"].(string); ok {
+					b := appendMsgpackHeader(nil, 1, 0x80, 0xde)
+					b = appendMsgpackString(b, "ack")
+					conn.Write(appendMsgpackString(b, chunk))
+				}
+			}
+		}()
+	}
+}
+
+// fluentEntries return the entries of given Forward or PackedForward msg.
+func fluentEntries(t *testing.T, msg []any) []any {
+	switch v := msg[1].(type) {
+	case []any:
+		return v
+	case string:
+		var entries []any
+		r := bufio.NewReader(bytes.NewReader([]byte(v)))
+		for {
+			e, err := readMsgpack(r)
+			if err != nil {
+				return entries
+			}
+			entries = append(entries, e)
+		}
+	}
+	t.Fatalf("unexpected entries type %T", msg[1])
+	return nil
+}
+
+func TestNewFluentWriter(t *testing.T) {
+	testCases := []struct {
+		name string
+		mode FluentMode
+		ack  bool
+	}{
+		{name: "Forward mode", mode: FluentForward},
+		{name: "PackedForward mode with ack", mode: FluentPackedForward, ack: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer ln.Close()
+			msgs := make(chan []any, 10)
+			go fluentServer(t, ln, msgs)
+
+			cnf := NewConfig(
+				WithFluentAddr("tcp", ln.Addr().String()),
+				WithFluentTag("kpm.api"),
+				WithFluentMode(tc.mode),
+				WithFluentAck(tc.ack),
+				WithFluentBatch(BatchConfig{Size: 10, Interval: time.Hour}),
+			)
+			fw := NewFluentWriter(DebugLevel, cnf)
+			assert.Equal(t, FLUENT, fw.Output())
+
+			wr := NewSlogLogger(fw)
+			wr.Init(time.Second)
+			wr.Inf("first", Num("number", 7))
+			wr.Err("second", Bool("ok", false))
+			wr.Flush(time.Second)
+
+			msg := <-msgs
+			require.Len(t, msg, 3)
+			assert.Equal(t, "kpm.api", msg[0])
+			entries := fluentEntries(t, msg)
+			require.Len(t, entries, 2)
+
+			first := entries[0].([]any)
+			assert.WithinDuration(t, time.Now(), first[0].(time.Time), time.Minute)
+			rec := first[1].(map[string]any)
+			assert.Equal(t, "first", rec["msg"])
+			assert.Equal(t, "INFO", rec["level"])
+			assert.Equal(t, int64(7), rec["number"])
+			assert.Equal(t, false, entries[1].([]any)[1].(map[string]any)["ok"])
+
+			opts := msg[2].(map[string]any)
+			if tc.mode == FluentPackedForward {
+				assert.Equal(t, int64(2), opts["size"])
+			}
+			if tc.ack {
+				assert.NotEmpty(t, opts["chunk"])
+			}
+		})
+	}
+	t.Run("Should buffer and reconnect while the server restarts", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		ln.Close()
+
+		cnf := NewConfig(
+			WithFluentAddr("tcp", addr),
+			WithFluentBatch(BatchConfig{Size: 1, Interval: time.Hour, MinBackoff: 10 * time.Millisecond, MaxRetry: 100}),
+		)
+		fw := NewFluentWriter(DebugLevel, cnf)
+		fw.Wait(10 * time.Millisecond)
+		_, err = fw.Writer().Write([]byte(`{"time":"2023-09-22T13:38:39+07:00","msg":"while down"}`))
+		require.NoError(t, err)
+
+		// the server is up again
+		time.Sleep(50 * time.Millisecond)
+		ln, err = net.Listen("tcp", addr)
+		require.NoError(t, err)
+		defer ln.Close()
+		msgs := make(chan []any, 10)
+		go fluentServer(t, ln, msgs)
+
+		select {
+		case msg := <-msgs:
+			assert.Equal(t, "app", msg[0])
+			entry := fluentEntries(t, msg)[0].([]any)
+			// the entry keep its time even when it's sent later
+			assert.Equal(t, int64(1695364719), entry[0].(time.Time).Unix())
+			assert.Equal(t, "while down", entry[1].(map[string]any)["msg"])
+		case <-time.After(5 * time.Second):
+			t.Fatal("the log is never sent")
+		}
+		fw.Flush(time.Second)
+	})
+}
+
+func TestFluentRecord(t *testing.T) {
+	assert.Equal(t, map[string]any{"message": "plain text"}, fluentRecord([]byte("plain text")))
+	assert.Equal(t, "hi", fluentRecord([]byte(`{"msg":"hi"}`))["msg"])
+}
@@ -0,0 +1,330 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GELFCompression define how the GELF UDP message is compressed.
+type GELFCompression int8
+
+const (
+	GELFNoCompression GELFCompression = iota // GELFNoCompression send the message as is
+	GELFGzip                                 // GELFGzip compress the message using gzip
+	GELFZlib                                 // GELFZlib compress the message using zlib
+)
+
+const (
+	// gelfDialTimeout the timeout used when connecting on write.
+	gelfDialTimeout = 5 * time.Second
+	// gelfWriteTimeout the timeout of each write, so the stalled server does
+	// not block the log caller forever.
+	gelfWriteTimeout = 5 * time.Second
+	// gelfChunkHeader the size of the GELF chunk header, which are the magic
+	// bytes, message id, sequence number and sequence count.
+	gelfChunkHeader = 12
+	// gelfMaxChunks the maximum number of chunks of a single message.
+	gelfMaxChunks = 128
+)
+
+var (
+	// errGELFBackoff returned when writing while waiting for the next
+	// reconnection attempt.
+	errGELFBackoff = errors.New("gelf: waiting to reconnect")
+	// errGELFTooLarge returned when the message need more than 128 chunks.
+	errGELFTooLarge = errors.New("gelf: message is too large")
+)
+
+// NewGELFWriter return Writer implementer that write logs to graylog GELF
+// input by given Config.GELF and set given lvl as the log Level. The UDP
+// message is compressed if configured and sent in chunks when it exceeds the
+// chunk size, while the TCP message is terminated by null byte. The
+// connection is established on Wait or the first write and reconnected with
+// exponential backoff whenever it's dropped.
+func NewGELFWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	g := &gelfOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.GELF, timeout: gelfWriteTimeout}
+	// set default value
+	if g.cnf.Network == "" {
+		g.cnf.Network = "udp"
+	}
+	if g.cnf.Addr == "" {
+		g.cnf.Addr = "localhost:12201"
+	}
+	if g.cnf.Host == "" {
+		g.cnf.Host, _ = os.Hostname()
+	}
+	if g.cnf.ChunkSize <= gelfChunkHeader {
+		g.cnf.ChunkSize = 1420
+	}
+	if g.cnf.MaxBackoff == 0 {
+		g.cnf.MaxBackoff = 30 * time.Second
+	}
+	return g
+}
+
+type gelfOutput struct {
+	lvl *AtomicLevel
+	cnf GELFConfig
+	// timeout the write timeout
+	timeout time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// Write implement io.Writer. Each given b is expected to be a single JSON
+// encoded log line.
+func (g *gelfOutput) Write(b []byte) (int, error) {
+	msgs, err := g.frame(gelfMessage(g.cnf.Host, time.Now(), b))
+	if err != nil {
+		return 0, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err = g.connect(gelfDialTimeout); err != nil {
+		return 0, err
+	}
+	if err = g.send(msgs); err != nil {
+		// the connection may be dropped by the server, reconnect and try
+		// once more
+		g.close()
+		if err = g.connect(gelfDialTimeout); err != nil {
+			return 0, err
+		}
+		if err = g.send(msgs); err != nil {
+			g.close()
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// frame return given GELF message as the datagrams to be sent over UDP, or a
+// single null byte terminated frame for TCP.
+func (g *gelfOutput) frame(msg []byte) ([][]byte, error) {
+	if g.cnf.Network != "udp" {
+		return [][]byte{append(msg, 0)}, nil
+	}
+
+	msg, err := gelfCompress(g.cnf.Compression, msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) <= g.cnf.ChunkSize {
+		return [][]byte{msg}, nil
+	}
+	return gelfChunks(msg, g.cnf.ChunkSize-gelfChunkHeader)
+}
+
+// send write given msgs to the current connection within the write timeout.
+// Must be called while holding the lock.
+func (g *gelfOutput) send(msgs [][]byte) error {
+	g.conn.SetWriteDeadline(time.Now().Add(g.timeout))
+	for _, m := range msgs {
+		if _, err := g.conn.Write(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connect dial the graylog server if not connected yet. Must be called while
+// holding the lock.
+func (g *gelfOutput) connect(timeout time.Duration) error {
+	if g.conn != nil {
+		return nil
+	}
+	if time.Now().Before(g.retryAt) {
+		return errGELFBackoff
+	}
+
+	conn, err := net.DialTimeout(g.cnf.Network, g.cnf.Addr, timeout)
+	if err != nil {
+		g.backoff = min(max(2*g.backoff, 100*time.Millisecond), g.cnf.MaxBackoff)
+		g.retryAt = time.Now().Add(g.backoff)
+		return err
+	}
+	g.conn, g.backoff, g.retryAt = conn, 0, time.Time{}
+	return nil
+}
+
+// close the current connection. Must be called while holding the lock.
+func (g *gelfOutput) close() {
+	if g.conn != nil {
+		g.conn.Close()
+		g.conn = nil
+	}
+}
+
+func (g *gelfOutput) Writer() io.Writer  { return g }
+func (g *gelfOutput) Output() Output     { return GELF }
+func (g *gelfOutput) Level() Level       { return g.lvl.Level() }
+func (g *gelfOutput) SetLevel(lvl Level) { g.lvl.SetLevel(lvl) }
+
+// Wait try to connect to the graylog server within given dur.
+func (g *gelfOutput) Wait(dur time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.connect(dur)
+}
+
+// Flush close the connection to the graylog server.
+func (g *gelfOutput) Flush(_ time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.close()
+}
+
+// gelfMessage transform given JSON encoded log line to GELF 1.1 message. The
+// msg become the short_message, the stacktrace become the full_message, the
+// time become the timestamp or given t if it's missing and the rest are
+// flattened as '_' prefixed additional fields.
+func gelfMessage(host string, t time.Time, line []byte) []byte {
+	line = bytes.TrimSpace(line)
+	short, full, lvl := string(line), "", InfoLevel
+	var fields bytes.Buffer
+
+	members, err := splitJSONObject(line)
+	if err == nil {
+		for _, m := range members {
+			switch m.key {
+			case "level":
+				lvl = ParseLevel(jsonString(m.val))
+			case "time":
+				t = jsonTime(m.val, t)
+			case "msg":
+				short = jsonString(m.val)
+			case "stacktrace":
+				full = jsonString(m.val)
+			default:
+				gelfFields(&fields, m.key, m.val)
+			}
+		}
+	}
+	if short == "" {
+		short = "-"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"version":"1.1","host":`)
+	buf.Write(jsonQuote(host))
+	buf.WriteString(`,"short_message":`)
+	buf.Write(jsonQuote(short))
+	if full != "" {
+		buf.WriteString(`,"full_message":`)
+		buf.Write(jsonQuote(full))
+	}
+	buf.WriteString(`,"timestamp":`)
+	buf.WriteString(strconv.FormatFloat(float64(t.UnixMicro())/1e6, 'f', 6, 64))
+	buf.WriteString(`,"level":`)
+	buf.WriteString(strconv.Itoa(syslogSeverity(lvl)))
+	buf.Write(fields.Bytes())
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// gelfFields write given raw JSON value as additional field named by given
+// key. Nested object is flattened by joining the keys with '_', while the
+// value other than string and number is written as its JSON string since
+// GELF only allow those two.
+func gelfFields(buf *bytes.Buffer, key string, val json.RawMessage) {
+	val = bytes.TrimSpace(val)
+	if len(val) > 0 && val[0] == '{' {
+		members, err := splitJSONObject(val)
+		if err == nil {
+			for _, m := range members {
+				gelfFields(buf, key+"_"+m.key, m.val)
+			}
+			return
+		}
+	}
+
+	buf.WriteString(",")
+	buf.Write(jsonQuote(gelfFieldName(key)))
+	buf.WriteByte(':')
+	if len(val) > 0 && (val[0] == '"' || val[0] == '-' || (val[0] >= '0' && val[0] <= '9')) {
+		buf.Write(val)
+		return
+	}
+	buf.Write(jsonQuote(string(val)))
+}
+
+// gelfFieldName return the '_' prefixed additional field name of given key
+// that only contains word characters, dot and dash. The reserved '_id' is
+// renamed to '_id_'.
+func gelfFieldName(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, key)
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + key
+}
+
+// gelfCompress compress given msg using given comp.
+func gelfCompress(comp GELFCompression, msg []byte) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch comp {
+	case GELFGzip:
+		w = gzip.NewWriter(&buf)
+	case GELFZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gelfChunks split given msg into GELF chunks that each carry at most given
+// size bytes of the msg.
+func gelfChunks(msg []byte, size int) ([][]byte, error) {
+	n := (len(msg) + size - 1) / size
+	if n > gelfMaxChunks {
+		return nil, errGELFTooLarge
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	chunks := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		part := msg[i*size : min((i+1)*size, len(msg))]
+		c := make([]byte, 0, gelfChunkHeader+len(part))
+		c = append(c, 0x1e, 0x0f)
+		c = append(c, id...)
+		c = append(c, byte(i), byte(n))
+		chunks = append(chunks, append(c, part...))
+	}
+	return chunks, nil
+}
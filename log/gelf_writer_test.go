@@ -0,0 +1,250 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readGELFDatagram read a single datagram from given pc.
+func readGELFDatagram(t *testing.T, pc net.PacketConn) []byte {
+	buf := make([]byte, 65536)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestNewGELFWriter(t *testing.T) {
+	t.Run("Default config", func(t *testing.T) {
+		wr := NewGELFWriter(InfoLevel, nil)
+		require.NotNil(t, wr)
+		assert.Equal(t, GELF, wr.Output())
+		assert.Equal(t, InfoLevel, wr.Level())
+		wr.(LevelSetter).SetLevel(DebugLevel)
+		assert.Equal(t, DebugLevel, wr.Level())
+
+		g := wr.(*gelfOutput)
+		assert.Equal(t, "udp", g.cnf.Network)
+		assert.Equal(t, "localhost:12201", g.cnf.Addr)
+		assert.Equal(t, 1420, g.cnf.ChunkSize)
+		assert.Equal(t, 30*time.Second, g.cnf.MaxBackoff)
+	})
+	t.Run("UDP should send additional fields", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer pc.Close()
+
+		cnf := NewConfig(WithGELFAddr("udp", pc.LocalAddr().String()), WithGELFHost("host-1"))
+		wr := NewZapLogger(NewGELFWriter(DebugLevel, cnf))
+		wr.Init(time.Second)
+		wr.Err("oops!!", String("hello", "world"), Num("id", 7), Bool("ok", true), Namespace("user", String("name", "kpm")))
+		wr.Flush(time.Second)
+
+		var msg map[string]any
+		require.NoError(t, json.Unmarshal(readGELFDatagram(t, pc), &msg))
+		assert.Equal(t, "1.1", msg["version"])
+		assert.Equal(t, "host-1", msg["host"])
+		assert.Equal(t, "oops!!", msg["short_message"])
+		assert.Equal(t, float64(3), msg["level"])
+		assert.InDelta(t, float64(time.Now().Unix()), msg["timestamp"], 5)
+		assert.Equal(t, "world", msg["_hello"])
+		assert.Equal(t, float64(7), msg["_id_"])
+		assert.Equal(t, "true", msg["_ok"])
+		assert.Equal(t, "kpm", msg["_user_name"])
+		assert.NotContains(t, msg, "_level")
+		assert.NotContains(t, msg, "_time")
+	})
+	t.Run("UDP should compress and chunk large message", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			comp   GELFCompression
+			reader func(io.Reader) (io.Reader, error)
+		}{
+			{
+				name: "Gzip",
+				comp: GELFGzip,
+				reader: func(r io.Reader) (io.Reader, error) {
+					return gzip.NewReader(r)
+				},
+			},
+			{
+				name: "Zlib",
+				comp: GELFZlib,
+				reader: func(r io.Reader) (io.Reader, error) {
+					return zlib.NewReader(r)
+				},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+				require.NoError(t, err)
+				defer pc.Close()
+
+				cnf := NewConfig(
+					WithGELFAddr("udp", pc.LocalAddr().String()),
+					WithGELFCompression(tc.comp),
+					WithGELFChunkSize(64),
+				)
+				wr := NewSlogLogger(NewGELFWriter(DebugLevel, cnf))
+				wr.Init(time.Second)
+				// random enough so it's still large after compressed
+				var long strings.Builder
+				for i := 0; i < 100; i++ {
+					long.WriteString(time.Duration(i * 7919).String())
+				}
+				wr.Inf("large", String("long", long.String()))
+				wr.Flush(time.Second)
+
+				first := readGELFDatagram(t, pc)
+				require.Equal(t, []byte{0x1e, 0x0f}, first[:2])
+				n := int(first[11])
+				require.Greater(t, n, 1)
+
+				parts := make([][]byte, n)
+				parts[first[10]] = first[gelfChunkHeader:]
+				for i := 1; i < n; i++ {
+					c := readGELFDatagram(t, pc)
+					assert.LessOrEqual(t, len(c), 64)
+					assert.Equal(t, first[2:10], c[2:10], "message id should be the same")
+					parts[c[10]] = c[gelfChunkHeader:]
+				}
+
+				r, err := tc.reader(bytes.NewReader(bytes.Join(parts, nil)))
+				require.NoError(t, err)
+				var msg map[string]any
+				require.NoError(t, json.NewDecoder(r).Decode(&msg))
+				assert.Equal(t, "large", msg["short_message"])
+				assert.Equal(t, float64(6), msg["level"])
+				assert.Equal(t, long.String(), msg["_long"])
+			})
+		}
+	})
+	t.Run("UDP should fail when message need too many chunks", func(t *testing.T) {
+		g := NewGELFWriter(DebugLevel, NewConfig(WithGELFChunkSize(13))).(*gelfOutput)
+		_, err := g.frame(bytes.Repeat([]byte{'a'}, gelfMaxChunks+1))
+		assert.ErrorIs(t, err, errGELFTooLarge)
+	})
+	t.Run("TCP should use null byte framing and no compression", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		msgs := make(chan []byte, 10)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			rd := bufio.NewReader(conn)
+			for {
+				b, err := rd.ReadBytes(0)
+				if err != nil {
+					return
+				}
+				msgs <- b
+			}
+		}()
+
+		cnf := NewConfig(WithGELFAddr("tcp", ln.Addr().String()), WithGELFCompression(GELFGzip))
+		gw := NewGELFWriter(DebugLevel, cnf)
+		gw.Wait(time.Second)
+		wr := NewZapLogger(gw)
+		wr.Init(time.Second)
+		wr.Wrn("first")
+		wr.Dbg("second")
+		wr.Flush(time.Second)
+
+		for _, expect := range []string{`"short_message":"first","timestamp":`, `"short_message":"second","timestamp":`} {
+			b := <-msgs
+			require.Equal(t, byte(0), b[len(b)-1])
+			assert.Contains(t, string(b), expect)
+			assert.True(t, json.Valid(b[:len(b)-1]))
+		}
+	})
+	t.Run("Should not block forever when the server stop reading", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			// accept but never read
+			conn, err := ln.Accept()
+			if err == nil {
+				<-stop
+				conn.Close()
+			}
+		}()
+
+		g := NewGELFWriter(DebugLevel, NewConfig(WithGELFAddr("tcp", ln.Addr().String()))).(*gelfOutput)
+		g.timeout = 50 * time.Millisecond
+		line := []byte(`{"level":"INFO","msg":"` + strings.Repeat("a", 1<<20) + `"}`)
+		for i := 0; i < 20; i++ {
+			start := time.Now()
+			g.Write(line)
+			require.Less(t, time.Since(start), time.Second)
+		}
+	})
+	t.Run("Should backoff when server is unreachable", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		ln.Close()
+
+		wr := NewGELFWriter(DebugLevel, NewConfig(WithGELFAddr("tcp", addr)))
+		_, err = wr.Writer().Write([]byte(`{"level":"INFO"}`))
+		require.Error(t, err)
+		_, err = wr.Writer().Write([]byte(`{"level":"INFO"}`))
+		assert.ErrorIs(t, err, errGELFBackoff)
+	})
+}
+
+func TestGELFMessage(t *testing.T) {
+	ts := time.Unix(1700000000, 123456000)
+
+	testCases := []struct {
+		name   string
+		sample string
+		expect string
+	}{
+		{
+			name:   "Should map level and move stacktrace to full message",
+			sample: `{"level":"FATAL","time":"now","msg":"boom","stacktrace":"main.go:1","tags":["a","b"],"nil":null,"my key":-1.5}`,
+			expect: `{"version":"1.1","host":"h","short_message":"boom","full_message":"main.go:1","timestamp":1700000000.123456,"level":1,"_tags":"[\"a\",\"b\"]","_nil":"null","_my_key":-1.5}`,
+		},
+		{
+			name:   "Should use the entry time as the timestamp",
+			sample: `{"level":"INFO","time":"2023-09-22T13:38:39+07:00","msg":"hi"}`,
+			expect: `{"version":"1.1","host":"h","short_message":"hi","timestamp":1695364719.000000,"level":6}`,
+		},
+		{
+			name:   "Should send non JSON line as short message",
+			sample: "plain text\n",
+			expect: `{"version":"1.1","host":"h","short_message":"plain text","timestamp":1700000000.123456,"level":6}`,
+		},
+		{
+			name:   "Should never send empty short message",
+			sample: `{"level":"TRACE","msg":""}`,
+			expect: `{"version":"1.1","host":"h","short_message":"-","timestamp":1700000000.123456,"level":7}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, string(gelfMessage("h", ts, []byte(tc.sample))))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPFormat define the request body format of the http Writer.
+type HTTPFormat int8
+
+const (
+	// HTTPNDJSON newline delimited JSON, one log per line.
+	HTTPNDJSON HTTPFormat = iota
+	// HTTPJSONArray JSON array of logs.
+	HTTPJSONArray
+)
+
+// NewHTTPWriter return Writer implementer that POST logs in batch to
+// arbitrary http collector by given Config.HTTP and set given lvl as the log
+// Level. The batch is retried with exponential backoff and jitter, and
+// spilled to disk if Config.HTTP.Batch.SpillDir is set when the collector is
+// down. Flush sends everything that is still queued.
+func NewHTTPWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	h := &httpOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.HTTP}
+	// set default value
+	if h.cnf.Client == nil {
+		h.cnf.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	h.bt = newBatcher(h.cnf.Batch, "http-"+shortHash(h.cnf.URL), h.send, nil)
+	return h
+}
+
+type httpOutput struct {
+	lvl *AtomicLevel
+	cnf HTTPConfig
+	bt  *batcher
+}
+
+// Write implement io.Writer.
+func (h *httpOutput) Write(p []byte) (int, error) {
+	h.bt.add(p)
+	return len(p), nil
+}
+
+// send POST given lines as a single request.
+func (h *httpOutput) send(ctx context.Context, lines [][]byte) error {
+	var body bytes.Buffer
+	switch h.cnf.Format {
+	case HTTPJSONArray:
+		body.WriteByte('[')
+		body.Write(bytes.Join(lines, []byte{','}))
+		body.WriteByte(']')
+	default:
+		for _, l := range lines {
+			body.Write(l)
+			body.WriteByte('\n')
+		}
+	}
+
+	req, err := newBatchRequest(ctx, h.cnf.URL, body.Bytes(), h.cnf.Gzip)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if h.cnf.Format == HTTPJSONArray {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range h.cnf.Headers {
+		req.Header.Set(k, v)
+	}
+	return doBatchRequest(h.cnf.Client, req)
+}
+
+func (h *httpOutput) Writer() io.Writer             { return h }
+func (h *httpOutput) Output() Output                { return HTTP }
+func (h *httpOutput) Level() Level                  { return h.lvl.Level() }
+func (h *httpOutput) SetLevel(lvl Level)            { h.lvl.SetLevel(lvl) }
+func (h *httpOutput) Wait(_ time.Duration)          {}
+func (h *httpOutput) Flush(dur time.Duration)       { h.bt.flush(dur) }
+func (h *httpOutput) LastError() (time.Time, error) { return h.bt.lastError() }
+
+// addDropHook implement dropReporter.
+func (h *httpOutput) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	h.bt.addDropHook(key, fn)
+}
+
+// newBatchRequest return new POST request to given url with given body that
+// is compressed using gzip if gz is true.
+func newBatchRequest(ctx context.Context, url string, body []byte, gz bool) (*http.Request, error) {
+	if gz {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+	if gz {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req, nil
+}
+
+// doBatchRequest send given req and return permanentError if the response
+// status is client error other than 429 Too Many Requests, so it's not
+// retried.
+func doBatchRequest(cl *http.Client, req *http.Request) error {
+	res, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkBatchResponse(res)
+}
+
+// checkBatchResponse return error if given res is not success, the error is
+// permanentError if the response status is client error other than 429 Too
+// Many Requests.
+func checkBatchResponse(res *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	io.Copy(io.Discard, res.Body)
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return fmt.Errorf("unexpected response status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return &permanentError{fmt.Errorf("unexpected response status %d: %s", res.StatusCode, bytes.TrimSpace(msg))}
+}
+
+// shortHash return the first 8 hex characters of the SHA-256 digest of given
+// s.
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
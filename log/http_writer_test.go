@@ -0,0 +1,141 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector httptest handler that record every request body.
+type collector struct {
+	mu     sync.Mutex
+	bodies []string
+	header http.Header
+	status int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodies = append(c.bodies, string(b))
+	c.header = r.Header.Clone()
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+	}
+}
+
+func (c *collector) all() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies
+}
+
+func TestNewHTTPWriter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		format      HTTPFormat
+		gzip        bool
+		contentType string
+		assertBody  func(t *testing.T, body string)
+	}{
+		{
+			name:        "NDJSON",
+			format:      HTTPNDJSON,
+			contentType: "application/x-ndjson",
+			assertBody: func(t *testing.T, body string) {
+				lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+				require.Len(t, lines, 2)
+				assert.Contains(t, lines[0], `"msg":"first"`)
+				assert.Contains(t, lines[1], `"msg":"second"`)
+			},
+		},
+		{
+			name:        "JSON array with gzip",
+			format:      HTTPJSONArray,
+			gzip:        true,
+			contentType: "application/json",
+			assertBody: func(t *testing.T, body string) {
+				assert.True(t, strings.HasPrefix(body, `[{`))
+				assert.True(t, strings.HasSuffix(body, `}]`))
+				assert.Contains(t, body, `"msg":"first","hello":"world"},{`)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			col := &collector{}
+			srv := httptest.NewServer(col)
+			defer srv.Close()
+
+			cnf := NewConfig(
+				WithHTTPURL(srv.URL),
+				WithHTTPHeader("X-Api-Key", "secret"),
+				WithHTTPFormat(tc.format),
+				WithHTTPGzip(tc.gzip),
+				WithHTTPBatch(BatchConfig{Size: 10, Interval: time.Hour}),
+			)
+			hw := NewHTTPWriter(DebugLevel, cnf)
+			assert.Equal(t, HTTP, hw.Output())
+			wr := NewZapLogger(hw)
+			wr.Init(time.Second)
+			wr.Inf("first", String("hello", "world"))
+			wr.Err("second")
+			// nothing is sent until flushed
+			assert.Empty(t, col.all())
+			wr.Flush(time.Second)
+
+			require.Len(t, col.all(), 1)
+			tc.assertBody(t, col.all()[0])
+			assert.Equal(t, "secret", col.header.Get("X-Api-Key"))
+			assert.Equal(t, tc.contentType, col.header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestCheckBatchResponse(t *testing.T) {
+	testCases := []struct {
+		status    int
+		err       bool
+		permanent bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNoContent},
+		{status: http.StatusBadRequest, err: true, permanent: true},
+		{status: http.StatusTooManyRequests, err: true},
+		{status: http.StatusServiceUnavailable, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			res := &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader("oops"))}
+			err := checkBatchResponse(res)
+			if !tc.err {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			_, ok := err.(*permanentError)
+			assert.Equal(t, tc.permanent, ok)
+		})
+	}
+}
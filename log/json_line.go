@@ -0,0 +1,106 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// errNotJSONObject returned when the log line is not a JSON object.
+var errNotJSONObject = errors.New("log line is not a JSON object")
+
+// jsonMember a top-level member of JSON object.
+type jsonMember struct {
+	key string
+	val json.RawMessage
+	raw []byte
+}
+
+// splitJSONObject return the top-level members of given JSON encoded object
+// in the same order as they are encoded.
+func splitJSONObject(b []byte) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, errNotJSONObject
+	}
+
+	var members []jsonMember
+	for dec.More() {
+		start := dec.InputOffset()
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+		var val json.RawMessage
+		if err = dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		// the raw member may start with the comma and whitespace that
+		// separate it from the previous member
+		raw := bytes.TrimLeft(b[start:dec.InputOffset()], ", \t\r\n")
+		members = append(members, jsonMember{key: key, val: val, raw: raw})
+	}
+	return members, nil
+}
+
+// joinJSONObject encode given members back as JSON object.
+func joinJSONObject(members []jsonMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(m.raw)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// jsonString return given raw JSON value as plain string, so JSON string is
+// unquoted and the rest are kept as is.
+func jsonString(val json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(val, &s); err == nil {
+		return s
+	}
+	return string(val)
+}
+
+// jsonTime return given raw JSON value as the entry time if it's RFC3339
+// encoded, otherwise return given now which is when the entry is written. The
+// entry time is encoded in seconds by default, so now is used instead when
+// it's still within the same second to keep the precision.
+func jsonTime(val json.RawMessage, now time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, jsonString(val))
+	if err != nil {
+		return now
+	}
+	if t.Nanosecond() == 0 && !now.Before(t) && now.Sub(t) < time.Second {
+		return now
+	}
+	return t
+}
+
+// entryTime return the time of given JSON encoded log line the same way as
+// jsonTime, or given now if there is none.
+func entryTime(line []byte, now time.Time) time.Time {
+	members, err := splitJSONObject(line)
+	if err != nil {
+		return now
+	}
+	for _, m := range members {
+		if m.key == "time" {
+			return jsonTime(m.val, now)
+		}
+	}
+	return now
+}
+
+// jsonQuote return given s as JSON string.
+func jsonQuote(s string) []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitJSONObject(t *testing.T) {
+	t.Run("Should keep the order", func(t *testing.T) {
+		line := []byte(`{"level":"INFO", "msg":"hi","obj":{"a":[1,2]},"num":1.5,"ok":true}`)
+		members, err := splitJSONObject(line)
+		require.NoError(t, err)
+		require.Len(t, members, 5)
+
+		var keys []string
+		for _, m := range members {
+			keys = append(keys, m.key)
+		}
+		assert.Equal(t, []string{"level", "msg", "obj", "num", "ok"}, keys)
+		assert.Equal(t, "INFO", jsonString(members[0].val))
+		assert.Equal(t, `{"a":[1,2]}`, jsonString(members[2].val))
+		assert.Equal(t, `"msg":"hi"`, string(members[1].raw))
+
+		assert.Equal(t, `{"msg":"hi","num":1.5}`, string(joinJSONObject([]jsonMember{members[1], members[3]})))
+	})
+	t.Run("Not JSON object", func(t *testing.T) {
+		_, err := splitJSONObject([]byte(`[1,2]`))
+		assert.ErrorIs(t, err, errNotJSONObject)
+		_, err = splitJSONObject([]byte(`hello`))
+		assert.ErrorIs(t, err, errNotJSONObject)
+	})
+}
+
+func TestEntryTime(t *testing.T) {
+	now := time.Date(2023, 9, 22, 13, 38, 39, 500, time.UTC)
+	old := time.Date(2023, 9, 22, 13, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, old, entryTime([]byte(`{"time":"2023-09-22T13:30:00Z","msg":"hi"}`), now).UTC())
+	assert.Equal(t, old.Add(time.Millisecond), entryTime([]byte(`{"time":"2023-09-22T13:30:00.001Z"}`), now).UTC())
+	// keep the precision within the same second
+	assert.Equal(t, now, entryTime([]byte(`{"time":"2023-09-22T13:38:39Z"}`), now))
+
+	assert.Equal(t, now, entryTime([]byte(`{"time":"now"}`), now))
+	assert.Equal(t, now, entryTime([]byte(`{"msg":"no time"}`), now))
+	assert.Equal(t, now, entryTime([]byte(`not json`), now))
+}
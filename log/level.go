@@ -1,13 +1,20 @@
 package log
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
 
 // A Level is a logging priority. Higher levels are more important.
 type Level int8
 
 const (
+	// TraceLevel even more verbose than Debug, usually used to trace the flow
+	// of a function.
+	TraceLevel Level = iota - 1
 	// DebugLevel most verbose logs, and are usually disabled in production.
-	DebugLevel Level = iota
+	DebugLevel
 	// InfoLevel is the default logging priority.
 	InfoLevel
 	// WarnLevel logs are more important than Info, but don't need individual
@@ -16,20 +23,117 @@ const (
 	// ErrorLevel logs are high-priority. If an application is running smoothly,
 	// it shouldn't generate any error-level logs.
 	ErrorLevel
+	// PanicLevel logs a message, flush all the Writer(s) then panics.
+	PanicLevel
+	// FatalLevel logs a message, flush all the Writer(s) then calls
+	// os.Exit(1).
+	FatalLevel
+	// InvalidLevel returned by ParseLevel for unrecognized level. No log is
+	// written at this level.
+	InvalidLevel
 )
 
 // ParseLevel parses a level based on the lower-case representation of the log
-// level.
+// level. Return InvalidLevel if given lvl is not recognized.
 func ParseLevel(lvl string) Level {
+	l, _ := parseLevel(lvl)
+	return l
+}
+
+// parseLevel parses a level based on the case-insensitive representation of
+// the log level. Return InvalidLevel and error if given lvl is not recognized.
+func parseLevel(lvl string) (Level, error) {
 	switch strings.ToLower(lvl) {
+	case "trace":
+		return TraceLevel, nil
 	case "debug":
-		return DebugLevel
+		return DebugLevel, nil
 	case "info":
-		return InfoLevel
+		return InfoLevel, nil
 	case "warning", "warn":
-		return WarnLevel
+		return WarnLevel, nil
 	case "error", "err":
-		return ErrorLevel
+		return ErrorLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	}
+	return InvalidLevel, fmt.Errorf("%w: %q", ErrInvalidLevel, lvl)
+}
+
+// String returns the upper-case representation of the log level.
+func (l Level) String() string {
+	switch l {
+	case TraceLevel:
+		return "TRACE"
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "PANIC"
+	case FatalLevel:
+		return "FATAL"
+	}
+	return "UNKNOWN"
+}
+
+// Valid return true if l is one of the recognized Level.
+func (l Level) Valid() bool {
+	return l >= TraceLevel && l < InvalidLevel
+}
+
+// MarshalText implement encoding.TextMarshaler, return error if l is not one
+// of the recognized Level.
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.Valid() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLevel, l)
 	}
-	return -1
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler, so Level can be loaded
+// straight from JSON, YAML or viper config using case-insensitive level name
+// such as 'debug' or 'WARN'. Return error if given text is not recognized.
+//
+// When using viper, make sure to add the text unmarshaler decode hook:
+//
+//	v.Unmarshal(&cnf, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc()))
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := parseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+// NewAtomicLevel return new AtomicLevel that use given lvl as the initial
+// Level.
+func NewAtomicLevel(lvl Level) *AtomicLevel {
+	a := new(AtomicLevel)
+	a.SetLevel(lvl)
+	return a
+}
+
+// AtomicLevel is an atomically changeable Level. It's safe to be read and
+// changed concurrently, which allows each Writer to change their Level at
+// runtime without restarting the Logger.
+type AtomicLevel struct {
+	lvl atomic.Int32
+}
+
+// Level returns the current Level.
+func (a *AtomicLevel) Level() Level {
+	return Level(a.lvl.Load())
+}
+
+// SetLevel change the current Level to given lvl.
+func (a *AtomicLevel) SetLevel(lvl Level) {
+	a.lvl.Store(int32(lvl))
 }
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ErrWriterNotFound returned by LevelHandler when there is no Writer
+	// registered with the given name.
+	ErrWriterNotFound = errors.New("writer not found")
+	// ErrLevelNotChangeable returned by LevelHandler when the Writer does not
+	// implement LevelSetter.
+	ErrLevelNotChangeable = errors.New("writer level is not changeable")
+	// ErrInvalidLevel returned when the given level is not recognized.
+	ErrInvalidLevel = errors.New("invalid log level")
+)
+
+// NewLevelHandler return LevelHandler that manage the Level of given Writer(s).
+// Each Writer is named after their Output, if there is more than one Writer
+// with the same Output then the next one will be suffixed with its order such
+// as 'file', 'file_2', 'file_3'.
+func NewLevelHandler(wr ...Writer) *LevelHandler {
+	h := &LevelHandler{wr: make(map[string]Writer)}
+	for _, w := range wr {
+		name := w.Output().String()
+		if _, ok := h.wr[name]; ok {
+			for i := 2; ; i++ {
+				n := name + "_" + strconv.Itoa(i)
+				if _, ok = h.wr[n]; !ok {
+					name = n
+					break
+				}
+			}
+		}
+		h.Register(name, w)
+	}
+	return h
+}
+
+// LevelHandler read and change the Level of named Writer(s) at runtime. It
+// also implements http.Handler so can be mounted directly to any http server.
+//
+// GET return the Level of all Writer(s) or just the given one by 'writer'
+// query param:
+//
+//	curl -X GET localhost:8080/log/level?writer=file
+//	{"writer":"file","level":"INFO"}
+//
+// PUT or POST change the Level of the Writer given by 'writer' query param
+// using either 'level' query param or JSON body:
+//
+//	curl -X PUT localhost:8080/log/level?writer=file -d '{"level":"debug"}'
+//	{"writer":"file","level":"DEBUG"}
+type LevelHandler struct {
+	mu sync.RWMutex
+	wr map[string]Writer
+}
+
+// Register add or replace given w as the Writer with given name.
+func (h *LevelHandler) Register(name string, w Writer) {
+	h.mu.Lock()
+	h.wr[name] = w
+	h.mu.Unlock()
+}
+
+// Get return the current Level of Writer with given name.
+func (h *LevelHandler) Get(name string) (Level, error) {
+	h.mu.RLock()
+	w, ok := h.wr[name]
+	h.mu.RUnlock()
+	if !ok {
+		return InvalidLevel, ErrWriterNotFound
+	}
+	return w.Level(), nil
+}
+
+// Set change the Level of Writer with given name to given lvl.
+func (h *LevelHandler) Set(name string, lvl Level) error {
+	if !lvl.Valid() {
+		return ErrInvalidLevel
+	}
+	h.mu.RLock()
+	w, ok := h.wr[name]
+	h.mu.RUnlock()
+	if !ok {
+		return ErrWriterNotFound
+	}
+	ls, ok := w.(LevelSetter)
+	if !ok {
+		return ErrLevelNotChangeable
+	}
+	ls.SetLevel(lvl)
+	return nil
+}
+
+// All return the current Level of all registered Writer(s) sorted by their
+// name.
+func (h *LevelHandler) All() []WriterLevel {
+	h.mu.RLock()
+	res := make([]WriterLevel, 0, len(h.wr))
+	for name, w := range h.wr {
+		res = append(res, WriterLevel{Writer: name, Level: w.Level().String()})
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Writer < res[j].Writer })
+	return res
+}
+
+// WriterLevel the response payload of LevelHandler.
+type WriterLevel struct {
+	Writer string `json:"writer"`
+	Level  string `json:"level,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServeHTTP implement http.Handler.
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("writer")
+
+	switch r.Method {
+	case http.MethodGet:
+		if name == "" {
+			writeLevelJSON(w, http.StatusOK, h.All())
+			return
+		}
+		lvl, err := h.Get(name)
+		if err != nil {
+			writeLevelJSON(w, http.StatusNotFound, WriterLevel{Writer: name, Error: err.Error()})
+			return
+		}
+		writeLevelJSON(w, http.StatusOK, WriterLevel{Writer: name, Level: lvl.String()})
+
+	case http.MethodPut, http.MethodPost:
+		lvlStr := r.URL.Query().Get("level")
+		if lvlStr == "" {
+			var req struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, WriterLevel{Writer: name, Error: err.Error()})
+				return
+			}
+			lvlStr = req.Level
+		}
+
+		err := h.Set(name, ParseLevel(lvlStr))
+		switch {
+		case errors.Is(err, ErrWriterNotFound):
+			writeLevelJSON(w, http.StatusNotFound, WriterLevel{Writer: name, Error: err.Error()})
+		case err != nil:
+			writeLevelJSON(w, http.StatusBadRequest, WriterLevel{Writer: name, Error: err.Error()})
+		default:
+			lvl, _ := h.Get(name)
+			writeLevelJSON(w, http.StatusOK, WriterLevel{Writer: name, Level: lvl.String()})
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelJSON(w, http.StatusMethodNotAllowed, WriterLevel{Writer: name, Error: "method not allowed"})
+	}
+}
+
+// writeLevelJSON write given v as JSON response with given status code.
+func writeLevelJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
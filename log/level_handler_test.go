@@ -0,0 +1,156 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedLevelWriter Writer implementer that does not implement LevelSetter.
+type fixedLevelWriter struct{ *consoleOutput }
+
+func (f fixedLevelWriter) SetLevel() {}
+
+func TestNewLevelHandler(t *testing.T) {
+	t.Run("Should name each Writer after their Output", func(t *testing.T) {
+		cns := NewConsoleWriter(InfoLevel)
+		fl1, _ := NewObserverWriter(DebugLevel, FILE)
+		fl2, _ := NewObserverWriter(WarnLevel, FILE)
+		h := NewLevelHandler(cns, fl1, fl2)
+
+		assert.Equal(t, []WriterLevel{
+			{Writer: "console", Level: "INFO"},
+			{Writer: "file", Level: "DEBUG"},
+			{Writer: "file_2", Level: "WARN"},
+		}, h.All())
+	})
+}
+
+func TestLevelHandler_GetSet(t *testing.T) {
+	cns := NewConsoleWriter(InfoLevel)
+	h := NewLevelHandler(cns)
+	h.Register("fixed", fixedLevelWriter{cns.(*consoleOutput)})
+
+	lvl, err := h.Get("console")
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, lvl)
+
+	require.NoError(t, h.Set("console", DebugLevel))
+	assert.Equal(t, DebugLevel, cns.Level())
+
+	_, err = h.Get("file")
+	assert.ErrorIs(t, err, ErrWriterNotFound)
+	assert.ErrorIs(t, h.Set("file", DebugLevel), ErrWriterNotFound)
+	assert.ErrorIs(t, h.Set("console", InvalidLevel), ErrInvalidLevel)
+	assert.ErrorIs(t, h.Set("fixed", ErrorLevel), ErrLevelNotChangeable)
+}
+
+func TestLevelHandler_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		expectCode int
+		expectBody string
+	}{
+		{
+			name:       "Get all Writer(s) level",
+			method:     http.MethodGet,
+			target:     "/",
+			expectCode: http.StatusOK,
+			expectBody: `[{"writer":"console","level":"INFO"},{"writer":"file","level":"ERROR"}]`,
+		},
+		{
+			name:       "Get single Writer level",
+			method:     http.MethodGet,
+			target:     "/?writer=file",
+			expectCode: http.StatusOK,
+			expectBody: `{"writer":"file","level":"ERROR"}`,
+		},
+		{
+			name:       "Get unknown Writer level",
+			method:     http.MethodGet,
+			target:     "/?writer=newrelic",
+			expectCode: http.StatusNotFound,
+			expectBody: `{"writer":"newrelic","error":"writer not found"}`,
+		},
+		{
+			name:       "Set Writer level using query param",
+			method:     http.MethodPut,
+			target:     "/?writer=file&level=debug",
+			expectCode: http.StatusOK,
+			expectBody: `{"writer":"file","level":"DEBUG"}`,
+		},
+		{
+			name:       "Set Writer level using JSON body",
+			method:     http.MethodPost,
+			target:     "/?writer=console",
+			body:       `{"level":"warn"}`,
+			expectCode: http.StatusOK,
+			expectBody: `{"writer":"console","level":"WARN"}`,
+		},
+		{
+			name:       "Set Writer level using malformed JSON body",
+			method:     http.MethodPut,
+			target:     "/?writer=console",
+			body:       `{"level":`,
+			expectCode: http.StatusBadRequest,
+			expectBody: `{"writer":"console","error":"unexpected EOF"}`,
+		},
+		{
+			name:       "Set Writer level with invalid level",
+			method:     http.MethodPut,
+			target:     "/?writer=console&level=verbose",
+			expectCode: http.StatusBadRequest,
+			expectBody: `{"writer":"console","error":"invalid log level"}`,
+		},
+		{
+			name:       "Set unknown Writer level",
+			method:     http.MethodPut,
+			target:     "/?writer=newrelic&level=debug",
+			expectCode: http.StatusNotFound,
+			expectBody: `{"writer":"newrelic","error":"writer not found"}`,
+		},
+		{
+			name:       "Unsupported method",
+			method:     http.MethodDelete,
+			target:     "/?writer=file",
+			expectCode: http.StatusMethodNotAllowed,
+			expectBody: `{"writer":"file","error":"method not allowed"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fl, _ := NewObserverWriter(ErrorLevel, FILE)
+			h := NewLevelHandler(NewConsoleWriter(InfoLevel), fl)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.JSONEq(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+func TestLevelHandlerWithLogger(t *testing.T) {
+	writer, obs := NewObserverWriter(InfoLevel, FILE)
+	wr := NewZapLogger(writer)
+	wr.Init(time.Microsecond)
+	h := NewLevelHandler(writer)
+
+	wr.Dbg("not written")
+	require.NoError(t, h.Set("file", DebugLevel))
+	wr.Dbg("written")
+
+	require.Equal(t, 1, obs.Len())
+	assert.True(t, obs.All()[0].EqualMsg("written"))
+}
@@ -1,9 +1,13 @@
 package log
 
 import (
+	"encoding/json"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseLevel(t *testing.T) {
@@ -12,6 +16,11 @@ func TestParseLevel(t *testing.T) {
 		sample string
 		expect Level
 	}{
+		{
+			name:   "Trace",
+			sample: "trace",
+			expect: TraceLevel,
+		},
 		{
 			name:   "Debug",
 			sample: "debug",
@@ -42,10 +51,25 @@ func TestParseLevel(t *testing.T) {
 			sample: "error",
 			expect: ErrorLevel,
 		},
+		{
+			name:   "Panic",
+			sample: "panic",
+			expect: PanicLevel,
+		},
+		{
+			name:   "Fatal",
+			sample: "fatal",
+			expect: FatalLevel,
+		},
+		{
+			name:   "Case-insensitive",
+			sample: "WARN",
+			expect: WarnLevel,
+		},
 		{
 			name:   "Unrecognized",
 			sample: "hello",
-			expect: -1,
+			expect: InvalidLevel,
 		},
 	}
 
@@ -55,3 +79,108 @@ func TestParseLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestLevel_String(t *testing.T) {
+	assert.Equal(t, "TRACE", TraceLevel.String())
+	assert.Equal(t, "DEBUG", DebugLevel.String())
+	assert.Equal(t, "INFO", InfoLevel.String())
+	assert.Equal(t, "WARN", WarnLevel.String())
+	assert.Equal(t, "ERROR", ErrorLevel.String())
+	assert.Equal(t, "PANIC", PanicLevel.String())
+	assert.Equal(t, "FATAL", FatalLevel.String())
+	assert.Equal(t, "UNKNOWN", InvalidLevel.String())
+}
+
+func TestLevel_Text(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var cnf config
+		require.NoError(t, json.Unmarshal([]byte(`{"level":"Trace"}`), &cnf))
+		assert.Equal(t, TraceLevel, cnf.Level)
+
+		err := json.Unmarshal([]byte(`{"level":"verbose"}`), &cnf)
+		assert.ErrorIs(t, err, ErrInvalidLevel)
+		assert.Equal(t, TraceLevel, cnf.Level)
+	})
+	t.Run("Marshal", func(t *testing.T) {
+		b, err := json.Marshal(config{Level: FatalLevel})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"level":"FATAL"}`, string(b))
+
+		_, err = json.Marshal(config{Level: InvalidLevel})
+		assert.ErrorIs(t, err, ErrInvalidLevel)
+	})
+}
+
+func TestTerminateLevel(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = os.Exit })
+
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(TraceLevel, FILE)
+			wr := fn(writer)
+			wr.Init(time.Microsecond)
+
+			wr.Trc("trace log")
+			require.PanicsWithValue(t, "panic log", func() { wr.Pnc("panic log") })
+			code = 0
+			wr.Ftl("fatal log", String("hello", "world"))
+			assert.Equal(t, 1, code)
+
+			logs := obs.TakeAll()
+			require.Len(t, logs, 3)
+			assert.True(t, logs[0].EqualLevel(TraceLevel))
+			assert.True(t, logs[1].EqualLevel(PanicLevel))
+			assert.True(t, logs[2].EqualLevel(FatalLevel))
+			assert.Equal(t, "world", logs[2].Get("hello"))
+		})
+	}
+}
+
+func TestAtomicLevel(t *testing.T) {
+	al := NewAtomicLevel(InfoLevel)
+	assert.Equal(t, InfoLevel, al.Level())
+
+	al.SetLevel(ErrorLevel)
+	assert.Equal(t, ErrorLevel, al.Level())
+}
+
+func TestChangeLevelAtRuntime(t *testing.T) {
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(ErrorLevel, FILE)
+			wr := fn(writer)
+			wr.Init(time.Microsecond)
+
+			wr.Dbg("should not be written")
+			require.Equal(t, 0, obs.Len())
+
+			writer.(LevelSetter).SetLevel(DebugLevel)
+			wr.Dbg("should be written")
+			require.Equal(t, 1, obs.Len())
+			assert.True(t, obs.All()[0].EqualMsg("should be written"))
+		})
+	}
+}
+
+func TestInvalidLevelDropEverything(t *testing.T) {
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(InvalidLevel, FILE)
+			wr := fn(writer)
+			wr.Init(time.Microsecond)
+
+			wr.Trc("trace")
+			wr.Dbg("debug")
+			wr.Inf("info")
+			wr.Wrn("warn")
+			wr.Err("error")
+			assert.Equal(t, 0, obs.Len())
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"fmt"
+	"time"
+)
+
 // Log object that holds data for each field inserted to each log message. How
 // Logger implementer is treating this object should read the field typ and
 // follow the guideline from Type and each of the supported types.
@@ -8,6 +13,8 @@ type Log struct {
 	key string
 	str string
 	num int
+	i64 int64
+	u64 uint64
 	flt float64
 	b   bool
 	any interface{}
@@ -31,6 +38,29 @@ const (
 	AnyType
 	// ErrorType use field err from error interface of Log as the value.
 	ErrorType
+	// DurationType use field i64 int64 of Log as the value in nanoseconds.
+	DurationType
+	// TimeType use field any of Log as the value which is time.Time.
+	TimeType
+	// Int64Type use field i64 int64 of Log as the value.
+	Int64Type
+	// Uint64Type use field u64 uint64 of Log as the value.
+	Uint64Type
+	// StringsType use field any of Log as the value which is []string.
+	StringsType
+	// IntsType use field any of Log as the value which is []int.
+	IntsType
+	// StringerType use field any of Log as the value which is fmt.Stringer.
+	StringerType
+	// BytesType use field any of Log as the value which is []byte and should
+	// be treated as UTF-8 string.
+	BytesType
+	// BinaryType use field any of Log as the value which is []byte and should
+	// be treated as opaque binary data such as base64 encoded in JSON.
+	BinaryType
+	// NamespaceType use field any of Log as the value which is []Log and
+	// should be treated as nested object.
+	NamespaceType
 )
 
 // String constructs a Log with the given key and value. This set the type
@@ -68,3 +98,65 @@ func Any(k string, any interface{}) Log {
 func Error(err error) Log {
 	return Log{typ: ErrorType, key: "error", err: err}
 }
+
+// Duration constructs a Log with the given key and value. This set the type
+// to DurationType.
+func Duration(k string, d time.Duration) Log {
+	return Log{typ: DurationType, key: k, i64: int64(d)}
+}
+
+// Time constructs a Log with the given key and value. This set the type
+// to TimeType.
+func Time(k string, t time.Time) Log {
+	return Log{typ: TimeType, key: k, any: t}
+}
+
+// Int64 constructs a Log with the given key and value. This set the type
+// to Int64Type.
+func Int64(k string, i int64) Log {
+	return Log{typ: Int64Type, key: k, i64: i}
+}
+
+// Uint64 constructs a Log with the given key and value. This set the type
+// to Uint64Type.
+func Uint64(k string, u uint64) Log {
+	return Log{typ: Uint64Type, key: k, u64: u}
+}
+
+// Strings constructs a Log with the given key and value. This set the type
+// to StringsType.
+func Strings(k string, ss []string) Log {
+	return Log{typ: StringsType, key: k, any: ss}
+}
+
+// Ints constructs a Log with the given key and value. This set the type
+// to IntsType.
+func Ints(k string, nums []int) Log {
+	return Log{typ: IntsType, key: k, any: nums}
+}
+
+// Stringer constructs a Log with the given key and the output of the value's
+// String method which is only called when the log entry is written. This set
+// the type to StringerType.
+func Stringer(k string, v fmt.Stringer) Log {
+	return Log{typ: StringerType, key: k, any: v}
+}
+
+// Bytes constructs a Log with the given key and UTF-8 encoded value such as
+// JSON payload. This set the type to BytesType.
+func Bytes(k string, b []byte) Log {
+	return Log{typ: BytesType, key: k, any: b}
+}
+
+// Binary constructs a Log with the given key and opaque binary value. This
+// set the type to BinaryType.
+func Binary(k string, b []byte) Log {
+	return Log{typ: BinaryType, key: k, any: b}
+}
+
+// Namespace constructs a Log with the given key that nests given Log(s) as
+// its fields. In JSON, this is like creating new object using given key and
+// Log(s) as the fields. This set the type to NamespaceType.
+func Namespace(k string, pr ...Log) Log {
+	return Log{typ: NamespaceType, key: k, any: pr}
+}
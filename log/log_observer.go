@@ -13,7 +13,7 @@ import (
 type ObservedLog struct {
 	mu   sync.RWMutex
 	logs []loggedLog
-	lvl  Level
+	lvl  *AtomicLevel
 	out  Output
 }
 
@@ -21,12 +21,18 @@ func (o *ObservedLog) Writer() io.Writer { return o }
 
 func (o *ObservedLog) Output() Output { return o.out }
 
-func (o *ObservedLog) Level() Level { return o.lvl }
+func (o *ObservedLog) Level() Level { return o.lvl.Level() }
+
+func (o *ObservedLog) SetLevel(lvl Level) { o.lvl.SetLevel(lvl) }
 
 func (o *ObservedLog) Wait(_ time.Duration) {}
 
 func (o *ObservedLog) Flush(_ time.Duration) {}
 
+// Encoder always return JSON Encoder, so the logs can be observed even when
+// the Output is CONSOLE.
+func (o *ObservedLog) Encoder() Encoder { return Encoder{} }
+
 func (o *ObservedLog) Write(p []byte) (n int, err error) {
 	m := make(map[string]any)
 	json.Unmarshal(bytes.TrimSpace(p), &m)
@@ -80,7 +86,7 @@ func (o *ObservedLog) TakeAll() []loggedLog {
 // and also return ObservedLog to help assert and check logged Log(s).
 func NewObserverWriter(lvl Level, out Output) (Writer, *ObservedLog) {
 	ol := &ObservedLog{
-		lvl: lvl,
+		lvl: NewAtomicLevel(lvl),
 		out: out,
 		mu:  sync.RWMutex{},
 	}
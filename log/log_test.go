@@ -3,8 +3,10 @@ package log
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLog(t *testing.T) {
@@ -41,4 +43,89 @@ func TestLog(t *testing.T) {
 	assert.Equal(t, "error", err.key)
 	assert.Equal(t, er, err.err)
 	assert.Equal(t, "oops", err.err.Error())
+
+	dur := Duration("latency", time.Second)
+	assert.Equal(t, DurationType, dur.typ)
+	assert.Equal(t, int64(time.Second), dur.i64)
+
+	now := time.Now()
+	tm := Time("at", now)
+	assert.Equal(t, TimeType, tm.typ)
+	assert.Equal(t, now, tm.any)
+
+	i64 := Int64("id", 1<<40)
+	assert.Equal(t, Int64Type, i64.typ)
+	assert.Equal(t, int64(1<<40), i64.i64)
+
+	u64 := Uint64("id", 1<<63)
+	assert.Equal(t, Uint64Type, u64.typ)
+	assert.Equal(t, uint64(1<<63), u64.u64)
+
+	ss := Strings("tags", []string{"a", "b"})
+	assert.Equal(t, StringsType, ss.typ)
+	assert.Equal(t, []string{"a", "b"}, ss.any)
+
+	ints := Ints("ids", []int{1, 2})
+	assert.Equal(t, IntsType, ints.typ)
+	assert.Equal(t, []int{1, 2}, ints.any)
+
+	sr := Stringer("level", InfoLevel)
+	assert.Equal(t, StringerType, sr.typ)
+	assert.Equal(t, InfoLevel, sr.any)
+
+	bs := Bytes("body", []byte("hello"))
+	assert.Equal(t, BytesType, bs.typ)
+	assert.Equal(t, []byte("hello"), bs.any)
+
+	bin := Binary("raw", []byte{0x1})
+	assert.Equal(t, BinaryType, bin.typ)
+	assert.Equal(t, []byte{0x1}, bin.any)
+
+	ns := Namespace("user", String("id", "007"))
+	assert.Equal(t, NamespaceType, ns.typ)
+	assert.Equal(t, "user", ns.key)
+	assert.Equal(t, []Log{String("id", "007")}, ns.any)
+}
+
+func TestTypedLogEncodedTheSameInBothBackend(t *testing.T) {
+	at := time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC)
+	pr := []Log{
+		Duration("latency", 1500*time.Millisecond),
+		Time("at", at),
+		Int64("id", 1<<40),
+		Uint64("uid", 1<<63),
+		Strings("tags", []string{"a", "b"}),
+		Ints("ids", []int{1, 2}),
+		Stringer("lvl", WarnLevel),
+		Bytes("body", []byte("hello")),
+		Binary("raw", []byte("hello")),
+		Namespace("user", String("id", "007"), Num("age", 7)),
+	}
+	expect := map[string]any{
+		"latency": "1.5s",
+		"at":      "2026-10-18T07:00:00Z",
+		"id":      float64(1 << 40),
+		"uid":     float64(1 << 63),
+		"tags":    []any{"a", "b"},
+		"ids":     []any{float64(1), float64(2)},
+		"lvl":     "WARN",
+		"body":    "hello",
+		"raw":     "aGVsbG8=",
+		"user":    map[string]any{"id": "007", "age": float64(7)},
+	}
+
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			writer, obs := NewObserverWriter(DebugLevel, FILE)
+			wr := fn(writer)
+			wr.Init(time.Microsecond)
+			wr.Inf("typed", pr...)
+
+			require.Equal(t, 1, obs.Len())
+			l := obs.All()[0]
+			for k, v := range expect {
+				assert.Equal(t, v, l.Get(k), k)
+			}
+		})
+	}
 }
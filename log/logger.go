@@ -1,6 +1,10 @@
 package log
 
-import "time"
+import (
+	"context"
+	"os"
+	"time"
+)
 
 // Logger unified front-end to log.
 type Logger interface {
@@ -21,6 +25,15 @@ type Logger interface {
 	//  - https://pkg.go.dev/go.uber.org/zap#Namespace
 	//  - https://pkg.go.dev/golang.org/x/exp/slog#Group
 	Group(key string, pr ...Log) Logger
+	// Ctx bind given ctx by adding the trace_id and span_id of the active
+	// OpenTelemetry span and any Log(s) from the registered CtxExtractor as
+	// structured context. Return the Logger itself if there is nothing to be
+	// added.
+	//
+	//  wr.Ctx(ctx).Inf("hello")
+	Ctx(ctx context.Context) Logger
+	// Trc logs a message at TraceLevel.
+	Trc(msg string, pr ...Log)
 	// Dbg logs a message at DebugLevel.
 	Dbg(msg string, pr ...Log)
 	// Inf logs a message at InfoLevel.
@@ -29,6 +42,25 @@ type Logger interface {
 	Wrn(msg string, pr ...Log)
 	// Err logs a message at ErrorLevel.
 	Err(msg string, pr ...Log)
+	// Pnc logs a message at PanicLevel, flush all the Writer(s) then panics
+	// with given msg, even if the PanicLevel is disabled.
+	Pnc(msg string, pr ...Log)
+	// Ftl logs a message at FatalLevel, flush all the Writer(s) then calls
+	// os.Exit(1), even if the FatalLevel is disabled.
+	Ftl(msg string, pr ...Log)
+}
+
+// exit terminate the process on FatalLevel, replaced on test.
+var exit = os.Exit
+
+// terminate call given flush then panics with given msg on PanicLevel or
+// exit the process on FatalLevel.
+func terminate(lvl Level, msg string, flush func()) {
+	flush()
+	if lvl == PanicLevel {
+		panic(msg)
+	}
+	exit(1)
 }
 
 // NewNop returns a no-op Logger. Do nothing and never writes out any logs.
@@ -40,9 +72,13 @@ type nopLogger struct{}
 
 func (n nopLogger) Init(_ time.Duration)            {}
 func (n nopLogger) Flush(_ time.Duration)           {}
-func (n nopLogger) With(_ ...Log) Logger            { return nil }
-func (n nopLogger) Group(_ string, _ ...Log) Logger { return nil }
+func (n nopLogger) With(_ ...Log) Logger            { return &n }
+func (n nopLogger) Group(_ string, _ ...Log) Logger { return &n }
+func (n nopLogger) Ctx(_ context.Context) Logger    { return &n }
+func (n nopLogger) Trc(_ string, _ ...Log)          {}
 func (n nopLogger) Dbg(_ string, _ ...Log)          {}
 func (n nopLogger) Inf(_ string, _ ...Log)          {}
 func (n nopLogger) Wrn(_ string, _ ...Log)          {}
 func (n nopLogger) Err(_ string, _ ...Log)          {}
+func (n nopLogger) Pnc(msg string, _ ...Log)        { panic(msg) }
+func (n nopLogger) Ftl(_ string, _ ...Log)          { exit(1) }
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -17,7 +18,9 @@ func TestNewNop(t *testing.T) {
 	t.Run("Should do nothing", func(t *testing.T) {
 		nl := NewNop()
 		assert.NotNil(t, nl)
-		assert.Nil(t, nl.With())
+		assert.Equal(t, nl, nl.With())
+		assert.Equal(t, nl, nl.Group("req"))
+		assert.Equal(t, nl, nl.Ctx(context.Background()))
 
 		// just run it, since its just do literary nothing
 		nl.Init(time.Microsecond)
@@ -26,5 +29,7 @@ func TestNewNop(t *testing.T) {
 		nl.Inf("")
 		nl.Wrn("")
 		nl.Err("")
+		nl.Trc("")
+		assert.Panics(t, func() { nl.Pnc("") })
 	})
 }
@@ -0,0 +1,168 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewLokiWriter return Writer implementer that push logs in batch to Grafana
+// Loki by given Config.Loki and set given lvl as the log Level. The Log keys
+// in Config.Loki.LabelKeys are promoted to stream labels, while the rest of
+// the fields stay in the log line.
+func NewLokiWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	l := &lokiOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.Loki}
+	// set default value
+	if l.cnf.Client == nil {
+		l.cnf.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if l.cnf.LabelKeys == nil {
+		l.cnf.LabelKeys = []string{"level"}
+	}
+	l.keys = make(map[string]bool, len(l.cnf.LabelKeys))
+	for _, k := range l.cnf.LabelKeys {
+		l.keys[k] = true
+	}
+	l.url = strings.TrimSuffix(l.cnf.URL, "/") + "/loki/api/v1/push"
+	l.bt = newBatcher(l.cnf.Batch, "loki-"+shortHash(l.url+l.cnf.TenantID), l.send, stampedEntry)
+	return l
+}
+
+type lokiOutput struct {
+	lvl  *AtomicLevel
+	cnf  LokiConfig
+	url  string
+	keys map[string]bool
+	bt   *batcher
+}
+
+// Write implement io.Writer. The entry time is queued along given p, so the
+// entry keep its original timestamp even when it's spilled to disk.
+func (l *lokiOutput) Write(p []byte) (int, error) {
+	ts := strconv.FormatInt(entryTime(p, time.Now()).UnixNano(), 10)
+	l.bt.add(append([]byte(ts+" "), bytes.TrimSpace(p)...))
+	return len(p), nil
+}
+
+// lokiPush the Loki push request body.
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// send push given lines grouped by their labels as a single request.
+func (l *lokiOutput) send(ctx context.Context, lines [][]byte) error {
+	var push lokiPush
+	streams := make(map[string]*lokiStream)
+	for _, line := range lines {
+		ts, entry, _ := bytes.Cut(line, []byte{' '})
+		labels, entry := l.labels(entry)
+
+		id := lokiStreamID(labels)
+		st, ok := streams[id]
+		if !ok {
+			st = &lokiStream{Stream: labels}
+			streams[id] = st
+			push.Streams = append(push.Streams, st)
+		}
+		st.Values = append(st.Values, [2]string{string(ts), string(entry)})
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return &permanentError{err}
+	}
+	req, err := newBatchRequest(ctx, l.url, body, false)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.cnf.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.cnf.TenantID)
+	}
+	if l.cnf.Username != "" || l.cnf.Password != "" {
+		req.SetBasicAuth(l.cnf.Username, l.cnf.Password)
+	}
+	return doBatchRequest(l.cnf.Client, req)
+}
+
+// labels return the stream labels of given JSON encoded log line and the line
+// without the promoted keys.
+func (l *lokiOutput) labels(line []byte) (map[string]string, []byte) {
+	labels := make(map[string]string, len(l.cnf.Labels)+len(l.keys))
+	for k, v := range l.cnf.Labels {
+		labels[lokiLabelName(k)] = v
+	}
+
+	members, err := splitJSONObject(line)
+	if err != nil {
+		return labels, line
+	}
+	rest := members[:0]
+	for _, m := range members {
+		if l.keys[m.key] {
+			labels[lokiLabelName(m.key)] = jsonString(m.val)
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return labels, joinJSONObject(rest)
+}
+
+func (l *lokiOutput) Writer() io.Writer             { return l }
+func (l *lokiOutput) Output() Output                { return LOKI }
+func (l *lokiOutput) Level() Level                  { return l.lvl.Level() }
+func (l *lokiOutput) SetLevel(lvl Level)            { l.lvl.SetLevel(lvl) }
+func (l *lokiOutput) Wait(_ time.Duration)          {}
+func (l *lokiOutput) Flush(dur time.Duration)       { l.bt.flush(dur) }
+func (l *lokiOutput) LastError() (time.Time, error) { return l.bt.lastError() }
+
+// addDropHook implement dropReporter.
+func (l *lokiOutput) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	l.bt.addDropHook(key, fn)
+}
+
+// lokiStreamID return the unique id of given labels.
+func lokiStreamID(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k + "=" + strconv.Quote(labels[k]) + ",")
+	}
+	return sb.String()
+}
+
+// lokiLabelName replace any character that is not allowed in Loki label name
+// with underscore, and prefix it with underscore if it starts with digit.
+func lokiLabelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		b[i] = '_'
+	}
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
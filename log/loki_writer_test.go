@@ -0,0 +1,93 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLokiWriter(t *testing.T) {
+	var (
+		push lokiPush
+		req  *http.Request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cnf := NewConfig(
+		WithLokiURL(srv.URL+"/"),
+		WithLokiTenant("team-a"),
+		WithLokiBasicAuth("user", "pass"),
+		WithLokiLabel("app", "kpm"),
+		WithLokiLabelKeys("level", "app_env"),
+		WithLokiBatch(BatchConfig{Size: 10, Interval: time.Hour}),
+	)
+	lw := NewLokiWriter(DebugLevel, cnf)
+	assert.Equal(t, LOKI, lw.Output())
+
+	wr := NewSlogLogger(lw)
+	wr.Init(time.Second)
+	wr = wr.With(String("app_env", "prod"))
+	wr.Inf("first", String("hello", "world"))
+	wr.Inf("second")
+	wr.Err("third")
+	wr.Flush(time.Second)
+
+	require.NotNil(t, req)
+	assert.Equal(t, "/loki/api/v1/push", req.URL.Path)
+	assert.Equal(t, "team-a", req.Header.Get("X-Scope-OrgID"))
+	user, pass, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", pass)
+
+	require.Len(t, push.Streams, 2)
+	inf := push.Streams[0]
+	assert.Equal(t, map[string]string{"app": "kpm", "level": "INFO", "app_env": "prod"}, inf.Stream)
+	require.Len(t, inf.Values, 2)
+	assert.NotEmpty(t, inf.Values[0][0])
+	// the promoted keys are removed from the line while the rest keep their order
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(inf.Values[0][1]), &line))
+	assert.Equal(t, "first", line["msg"])
+	assert.Equal(t, "world", line["hello"])
+	assert.NotContains(t, line, "level")
+	assert.NotContains(t, line, "app_env")
+
+	err := push.Streams[1]
+	assert.Equal(t, "ERROR", err.Stream["level"])
+	require.Len(t, err.Values, 1)
+	assert.Contains(t, err.Values[0][1], `"msg":"third"`)
+}
+
+func TestLokiWriterEntryTime(t *testing.T) {
+	var push lokiPush
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	lw := NewLokiWriter(DebugLevel, NewConfig(WithLokiURL(srv.URL), WithLokiBatch(BatchConfig{Size: 10, Interval: time.Hour})))
+	lw.Writer().Write([]byte(`{"level":"INFO","time":"2023-09-22T13:38:39+07:00","msg":"late"}` + "\n"))
+	lw.Flush(time.Second)
+
+	require.Len(t, push.Streams, 1)
+	require.Len(t, push.Streams[0].Values, 1)
+	assert.Equal(t, "1695364719000000000", push.Streams[0].Values[0][0])
+}
+
+func TestLokiLabelName(t *testing.T) {
+	assert.Equal(t, "app_env", lokiLabelName("app_env"))
+	assert.Equal(t, "user_id", lokiLabelName("user.id"))
+	assert.Equal(t, "_1st", lokiLabelName("1st"))
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// appendMsgpack append the MessagePack encoding of given v that is decoded
+// from JSON using json.Decoder.UseNumber to given b.
+func appendMsgpack(b []byte, v any) []byte {
+	switch vv := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if vv {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return appendMsgpackInt(b, i)
+		}
+		if u, err := strconv.ParseUint(vv.String(), 10, 64); err == nil {
+			return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+		}
+		f, _ := vv.Float64()
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+	case string:
+		return appendMsgpackString(b, vv)
+	case []any:
+		b = appendMsgpackHeader(b, len(vv), 0x90, 0xdc)
+		for _, e := range vv {
+			b = appendMsgpack(b, e)
+		}
+		return b
+	case map[string]any:
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackHeader(b, len(vv), 0x80, 0xde)
+		for _, k := range keys {
+			b = appendMsgpackString(b, k)
+			b = appendMsgpack(b, vv[k])
+		}
+		return b
+	}
+	return appendMsgpackString(b, fmt.Sprint(v))
+}
+
+// appendMsgpackInt append the smallest MessagePack encoding of given i.
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxInt8, i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+// appendMsgpackString append the MessagePack str encoding of given s.
+func appendMsgpackString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackBin append the MessagePack bin encoding of given p.
+func appendMsgpackBin(b []byte, p []byte) []byte {
+	n := len(p)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, p...)
+}
+
+// appendMsgpackHeader append the array or map header with given n length.
+// Given fix is the fixarray or fixmap prefix and given ext16 is the array16
+// or map16 prefix that is followed by the 32-bit one.
+func appendMsgpackHeader(b []byte, n int, fix, ext16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, ext16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, ext16+1), uint32(n))
+}
+
+// appendMsgpackEventTime append the Fluent EventTime extension type of given
+// t.
+func appendMsgpackEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// errMsgpackType returned when decoding unsupported MessagePack type.
+var errMsgpackType = errors.New("msgpack: unsupported type")
+
+// readMsgpackStringMap decode a MessagePack map that only has string keys and
+// values from given r.
+func readMsgpackStringMap(r *bufio.Reader) (map[string]string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	switch {
+	case c&0xf0 == 0x80:
+		n = int(c & 0x0f)
+	case c == 0xde:
+		n, err = readMsgpackLen(r, 2)
+	case c == 0xdf:
+		n, err = readMsgpackLen(r, 4)
+	default:
+		return nil, errMsgpackType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpackString(r)
+		if err != nil {
+			return nil, err
+		}
+		if m[k], err = readMsgpackString(r); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// readMsgpackString decode a MessagePack str or bin from given r.
+func readMsgpackString(r *bufio.Reader) (string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	var n int
+	switch {
+	case c&0xe0 == 0xa0:
+		n = int(c & 0x1f)
+	case c == 0xd9, c == 0xc4:
+		n, err = readMsgpackLen(r, 1)
+	case c == 0xda, c == 0xc5:
+		n, err = readMsgpackLen(r, 2)
+	case c == 0xdb, c == 0xc6:
+		n, err = readMsgpackLen(r, 4)
+	default:
+		return "", errMsgpackType
+	}
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readMsgpackLen read big-endian unsigned integer with given size in bytes
+// from given r.
+func readMsgpackLen(r *bufio.Reader, size int) (int, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+	var n int
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n, nil
+}
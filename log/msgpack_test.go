@@ -0,0 +1,147 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendMsgpack(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample any
+		expect []byte
+	}{
+		{name: "Nil", sample: nil, expect: []byte{0xc0}},
+		{name: "True", sample: true, expect: []byte{0xc3}},
+		{name: "False", sample: false, expect: []byte{0xc2}},
+		{name: "Positive fixint", sample: json.Number("7"), expect: []byte{0x07}},
+		{name: "Negative fixint", sample: json.Number("-1"), expect: []byte{0xff}},
+		{name: "Int8", sample: json.Number("-100"), expect: []byte{0xd0, 0x9c}},
+		{name: "Int16", sample: json.Number("1000"), expect: []byte{0xd1, 0x03, 0xe8}},
+		{name: "Int32", sample: json.Number("100000"), expect: []byte{0xd2, 0x00, 0x01, 0x86, 0xa0}},
+		{name: "Int64", sample: json.Number("4294967296"), expect: []byte{0xd3, 0, 0, 0, 1, 0, 0, 0, 0}},
+		{name: "Uint64", sample: json.Number("18446744073709551615"), expect: []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{name: "Float64", sample: json.Number("1.5"), expect: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{name: "Fixstr", sample: "hi", expect: []byte{0xa2, 'h', 'i'}},
+		{name: "Str8", sample: strings.Repeat("a", 32), expect: append([]byte{0xd9, 32}, strings.Repeat("a", 32)...)},
+		{name: "Fixarray", sample: []any{true, nil}, expect: []byte{0x92, 0xc3, 0xc0}},
+		{name: "Fixmap with sorted keys", sample: map[string]any{"b": true, "a": "x"}, expect: []byte{0x82, 0xa1, 'a', 0xa1, 'x', 0xa1, 'b', 0xc3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, appendMsgpack(nil, tc.sample))
+		})
+	}
+}
+
+func TestAppendMsgpackEventTime(t *testing.T) {
+	b := appendMsgpackEventTime(nil, time.Unix(1, 2))
+	assert.Equal(t, []byte{0xd7, 0x00, 0, 0, 0, 1, 0, 0, 0, 2}, b)
+}
+
+func TestReadMsgpackStringMap(t *testing.T) {
+	b := appendMsgpackHeader(nil, 1, 0x80, 0xde)
+	b = appendMsgpackString(b, "ack")
+	b = appendMsgpackString(b, "chunk-id")
+	m, err := readMsgpackStringMap(bufio.NewReader(bytes.NewReader(b)))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ack": "chunk-id"}, m)
+
+	_, err = readMsgpackStringMap(bufio.NewReader(bytes.NewReader([]byte{0xc0})))
+	assert.ErrorIs(t, err, errMsgpackType)
+}
+
+// readMsgpack decode any MessagePack value that is produced by appendMsgpack
+// from given r. Only used to assert the encoded message in test.
+func readMsgpack(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	readN := func(n int) []byte {
+		b := make([]byte, n)
+		io.ReadFull(r, b)
+		return b
+	}
+	readArray := func(n int) ([]any, error) {
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = readMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	readMap := func(n int) (map[string]any, error) {
+		m := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			k, err := readMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			if m[k.(string)], err = readMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return readArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return string(readN(int(c & 0x1f))), nil
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		return string(readN(int(readN(1)[0]))), nil
+	case 0xc5, 0xda:
+		return string(readN(int(binary.BigEndian.Uint16(readN(2))))), nil
+	case 0xc6, 0xdb:
+		return string(readN(int(binary.BigEndian.Uint32(readN(4))))), nil
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xcf:
+		return binary.BigEndian.Uint64(readN(8)), nil
+	case 0xd0:
+		return int64(int8(readN(1)[0])), nil
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(readN(2)))), nil
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(readN(4)))), nil
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xd7:
+		b := readN(9)
+		return time.Unix(int64(binary.BigEndian.Uint32(b[1:5])), int64(binary.BigEndian.Uint32(b[5:]))), nil
+	case 0xdc:
+		return readArray(int(binary.BigEndian.Uint16(readN(2))))
+	case 0xde:
+		return readMap(int(binary.BigEndian.Uint16(readN(2))))
+	}
+	return nil, errors.New("unsupported type")
+}
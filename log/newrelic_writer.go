@@ -2,9 +2,12 @@ package log
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -12,36 +15,188 @@ import (
 
 // NewNewrelicWriter return Writer implementer that ingest logs directly to
 // newrelic server by given Config.NR and set given Level as the log level.
+//
+// Deprecated: it panics when the newrelic app failed to be created, use
+// NewNRWriter instead.
 func NewNewrelicWriter(lvl Level, cnf *Config) Writer {
+	wr, err := NewNRWriter(lvl, cnf)
+	if err != nil {
+		panic(err)
+	}
+	return wr
+}
+
+// NewNRWriter return Writer implementer that record logs directly to newrelic
+// server by given Config.NR and set given lvl as the log Level. Each log is
+// recorded with its severity, timestamp and the structured fields as the
+// attributes. Use NRCtxExtractor to link the logs to the active transaction.
+// The harvest failure is reported by LastError only if the newrelic app is
+// created by this Writer, since the one given by Config.NR.App use its own
+// logger.
+func NewNRWriter(lvl Level, cnf *Config) (Writer, error) {
 	if cnf == nil {
 		cnf = &Config{}
 	}
 
+	n := &newrelicOutput{lvl: NewAtomicLevel(lvl), nr: cnf.NR.App}
+	if n.nr != nil {
+		return n, nil
+	}
 	nr, err := newrelic.NewApplication(
 		newrelic.ConfigAppName(cnf.NR.Name),
 		newrelic.ConfigLicense(cnf.NR.License),
 		newrelic.ConfigInfoLogger(os.Stdout),
+		func(c *newrelic.Config) { c.Logger = &nrHealthLogger{Logger: c.Logger, out: n} },
 	)
 	if err != nil {
-		err = errors.New("failed to init newrelic app: " + err.Error())
-		panic(err)
+		return nil, errors.New("failed to init newrelic app: " + err.Error())
 	}
-	return &newrelicOutput{lvl: lvl, nr: nr}
+	n.nr, n.owned = nr, true
+	return n, nil
+}
+
+// NRCtxExtractor is CtxExtractor that add the 'trace.id' and 'span.id' of
+// the newrelic transaction inside given ctx, which newrelic use to link the
+// logs to the transaction.
+func NRCtxExtractor(ctx context.Context) []Log {
+	md := newrelic.FromContext(ctx).GetLinkingMetadata()
+	if md.TraceID == "" {
+		return nil
+	}
+	pr := []Log{String("trace.id", md.TraceID)}
+	if md.SpanID != "" {
+		pr = append(pr, String("span.id", md.SpanID))
+	}
+	return pr
 }
 
 type newrelicOutput struct {
 	nr  *newrelic.Application
-	lvl Level
+	lvl *AtomicLevel
+	// owned whether nr is created by this Writer, so it should be shut down
+	// on Flush.
+	owned bool
+
+	mu       sync.Mutex
+	failedAt time.Time
+	failErr  error
 }
 
 // Write implement io.Writer.
 func (n *newrelicOutput) Write(p []byte) (_ int, err error) {
-	msg := string(bytes.TrimSpace(p))
-	n.nr.RecordLog(newrelic.LogData{Message: msg})
+	n.nr.RecordLog(nrLogData(time.Now(), p))
 	return len(p), nil
 }
-func (n *newrelicOutput) Writer() io.Writer       { return n }
-func (n *newrelicOutput) Output() Output          { return NEWRELIC }
-func (n *newrelicOutput) Level() Level            { return n.lvl }
-func (n *newrelicOutput) Wait(dur time.Duration)  { n.nr.WaitForConnection(dur) }
-func (n *newrelicOutput) Flush(dur time.Duration) { n.nr.Shutdown(dur) }
+func (n *newrelicOutput) Writer() io.Writer      { return n }
+func (n *newrelicOutput) Output() Output         { return NEWRELIC }
+func (n *newrelicOutput) Level() Level           { return n.lvl.Level() }
+func (n *newrelicOutput) SetLevel(lvl Level)     { n.lvl.SetLevel(lvl) }
+func (n *newrelicOutput) Wait(dur time.Duration) { n.nr.WaitForConnection(dur) }
+
+// LastError return when newrelic failed to deliver the harvested data and its
+// error.
+func (n *newrelicOutput) LastError() (time.Time, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.failedAt, n.failErr
+}
+
+// fail record given err as the last delivery error.
+func (n *newrelicOutput) fail(err error) {
+	n.mu.Lock()
+	n.failedAt, n.failErr = time.Now(), err
+	n.mu.Unlock()
+}
+
+// Flush shut down the newrelic app within given dur, unless it's given by
+// Config.NR.App.
+func (n *newrelicOutput) Flush(dur time.Duration) {
+	if n.owned {
+		n.nr.Shutdown(dur)
+	}
+}
+
+// nrHealthLogger newrelic.Logger implementer that report the harvest and
+// connect failure of the newrelic app to the Writer, since newrelic does not
+// return it anywhere else.
+type nrHealthLogger struct {
+	newrelic.Logger
+	out *newrelicOutput
+}
+
+func (n *nrHealthLogger) Warn(msg string, ctx map[string]any) {
+	switch msg {
+	case "harvest failure", "application connect failure":
+		err, _ := ctx["error"].(string)
+		n.out.fail(errors.New("newrelic: " + msg + ": " + err))
+	}
+	n.Logger.Warn(msg, ctx)
+}
+func (n *nrHealthLogger) Error(msg string, ctx map[string]any) {
+	if msg == "application disconnected" {
+		n.out.fail(errors.New("newrelic: " + msg))
+	}
+	n.Logger.Error(msg, ctx)
+}
+
+// nrLogData transform given JSON encoded log line to newrelic LogData that is
+// recorded at the entry time, or given t if it's missing. The nested object is
+// flattened by joining the keys with '.'.
+func nrLogData(t time.Time, line []byte) newrelic.LogData {
+	line = bytes.TrimSpace(line)
+	data := newrelic.LogData{Timestamp: t.UnixMilli(), Message: string(line)}
+	members, err := splitJSONObject(line)
+	if err != nil {
+		return data
+	}
+
+	data.Message = ""
+	for _, m := range members {
+		switch m.key {
+		case "level":
+			data.Severity = jsonString(m.val)
+		case "time":
+			data.Timestamp = jsonTime(m.val, t).UnixMilli()
+		case "msg":
+			data.Message = jsonString(m.val)
+		default:
+			if data.Attributes == nil {
+				data.Attributes = make(map[string]any)
+			}
+			nrAttributes(data.Attributes, m.key, m.val)
+		}
+	}
+	return data
+}
+
+// nrAttributes add given raw JSON value to given attrs as the attribute named
+// by given key. Array is added as its JSON string since newrelic only support
+// scalar attribute value.
+func nrAttributes(attrs map[string]any, key string, val json.RawMessage) {
+	dec := json.NewDecoder(bytes.NewReader(val))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		attrs[key] = string(val)
+		return
+	}
+
+	switch vv := v.(type) {
+	case nil:
+	case map[string]any:
+		members, _ := splitJSONObject(val)
+		for _, m := range members {
+			nrAttributes(attrs, key+"."+m.key, m.val)
+		}
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			attrs[key] = i
+			return
+		}
+		attrs[key], _ = vv.Float64()
+	case []any:
+		attrs[key] = string(bytes.TrimSpace(val))
+	default:
+		attrs[key] = vv
+	}
+}
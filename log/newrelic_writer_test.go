@@ -1,8 +1,12 @@
 package log
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,3 +34,114 @@ func TestNewNewrelicWriter(t *testing.T) {
 		wr.Flush(-1)
 	})
 }
+
+func TestNRHealthLogger(t *testing.T) {
+	out := &newrelicOutput{}
+	l := &nrHealthLogger{Logger: newrelic.NewDebugLogger(io.Discard), out: out}
+	l.Warn("unable to create harvest data", nil)
+	l.Error("unable to create trace observer", nil)
+	at, err := out.LastError()
+	assert.True(t, at.IsZero())
+	assert.NoError(t, err)
+
+	l.Warn("harvest failure", map[string]any{"error": "timeout"})
+	at, err = out.LastError()
+	assert.False(t, at.IsZero())
+	assert.EqualError(t, err, "newrelic: harvest failure: timeout")
+
+	l.Error("application disconnected", nil)
+	_, err = out.LastError()
+	assert.EqualError(t, err, "newrelic: application disconnected")
+}
+
+func TestNewNRWriter(t *testing.T) {
+	t.Run("Should return error instead of panic", func(t *testing.T) {
+		wr, err := NewNRWriter(DebugLevel, nil)
+		require.EqualError(t, err, "failed to init newrelic app: license length is not 40")
+		assert.Nil(t, wr)
+	})
+	t.Run("Should use the given app", func(t *testing.T) {
+		app, err := newrelic.NewApplication(
+			newrelic.ConfigAppName("name"),
+			newrelic.ConfigEnabled(false),
+		)
+		require.NoError(t, err)
+
+		wr, err := NewNRWriter(InfoLevel, NewConfig(WithNRApp(app)))
+		require.NoError(t, err)
+		assert.Equal(t, NEWRELIC, wr.Output())
+		assert.Equal(t, InfoLevel, wr.Level())
+		assert.Same(t, app, wr.(*newrelicOutput).nr)
+		assert.False(t, wr.(*newrelicOutput).owned)
+
+		// just run
+		wr.Writer().Write([]byte(`{"level":"INFO","msg":"message"}`))
+		wr.Flush(time.Second)
+	})
+}
+
+func TestNRLogData(t *testing.T) {
+	ts := time.UnixMilli(1700000000123)
+
+	testCases := []struct {
+		name   string
+		sample string
+		expect newrelic.LogData
+	}{
+		{
+			name:   "Should record the structured fields as attributes",
+			sample: `{"level":"ERROR","time":"now","msg":"oops!!","id":7,"ratio":1.5,"ok":true,"nil":null,"tags":["a"],"user":{"name":"kpm","role":{"id":1}}}` + "\n",
+			expect: newrelic.LogData{
+				Timestamp: 1700000000123,
+				Severity:  "ERROR",
+				Message:   "oops!!",
+				Attributes: map[string]any{
+					"id":           int64(7),
+					"ratio":        1.5,
+					"ok":           true,
+					"tags":         `["a"]`,
+					"user.name":    "kpm",
+					"user.role.id": int64(1),
+				},
+			},
+		},
+		{
+			name:   "Should record at the entry time",
+			sample: `{"level":"INFO","time":"2023-09-22T13:38:39.5+07:00","msg":"hi"}`,
+			expect: newrelic.LogData{Timestamp: 1695364719500, Severity: "INFO", Message: "hi"},
+		},
+		{
+			name:   "Should record non JSON line as message",
+			sample: "plain text\n",
+			expect: newrelic.LogData{Timestamp: 1700000000123, Message: "plain text"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, nrLogData(ts, []byte(tc.sample)))
+		})
+	}
+}
+
+func TestNRCtxExtractor(t *testing.T) {
+	t.Run("Should return nothing when there is no transaction", func(t *testing.T) {
+		assert.Nil(t, NRCtxExtractor(context.Background()))
+	})
+	t.Run("Should return the trace and span id of the transaction", func(t *testing.T) {
+		app, err := newrelic.NewApplication(
+			newrelic.ConfigAppName("name"),
+			newrelic.ConfigEnabled(false),
+			newrelic.ConfigDistributedTracerEnabled(true),
+		)
+		require.NoError(t, err)
+		txn := app.StartTransaction("handler")
+		defer txn.End()
+
+		md := txn.GetLinkingMetadata()
+		require.NotEmpty(t, md.TraceID)
+		pr := NRCtxExtractor(newrelic.NewContext(context.Background(), txn))
+		require.NotEmpty(t, pr)
+		assert.Equal(t, String("trace.id", md.TraceID), pr[0])
+	})
+}
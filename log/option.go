@@ -0,0 +1,189 @@
+package log
+
+// Option configure the Logger when building it using NewZapLoggerWithOptions
+// or NewSlogLoggerWithOptions.
+type Option func(*options)
+
+// options holds any optional configuration applied to the Logger.
+type options struct {
+	sampling   []writerSampling
+	redaction  []writerRedaction
+	encoders   []writerEncoder
+	extractors []CtxExtractor
+	onWriteErr []WriteErrorHandler
+	caller     bool
+	callerSkip int
+	stack      bool
+	stackLvl   Level
+}
+
+// newOptions return new options after applying given opts.
+func newOptions(opts ...Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSampling set the sampling config for given Writer(s), or all Writer(s)
+// if none is given. If there is more than one sampling config that match the
+// same Writer then the last one is used.
+func WithSampling(cnf SamplingConfig, wr ...Writer) Option {
+	return func(o *options) {
+		o.sampling = append(o.sampling, writerSampling{cnf: cnf, wr: wr})
+	}
+}
+
+// WithRedaction redact the sensitive fields based on given RedactPolicy for
+// given Writer(s), or all Writer(s) if none is given. Can be applied multiple
+// times, so each Writer may have different policy. Example redact 'password'
+// in all Writer(s) but only redact 'phone' in NEWRELIC Writer.
+func WithRedaction(p RedactPolicy, wr ...Writer) Option {
+	return func(o *options) {
+		o.redaction = append(o.redaction, writerRedaction{policy: p, wr: wr})
+	}
+}
+
+// WithEncoder set how the log entries are encoded for given Writer(s), or all
+// Writer(s) if none is given. If there is more than one Encoder that match
+// the same Writer then the last one is used. This take precedence over the
+// Encoder declared by the Writer through EncoderProvider. PLATFORM and SYSLOG
+// Writer always use the default JSON encoding.
+func WithEncoder(enc Encoder, wr ...Writer) Option {
+	return func(o *options) {
+		o.encoders = append(o.encoders, writerEncoder{enc: enc, wr: wr})
+	}
+}
+
+// WithCtxExtractor register given CtxExtractor(s) that will be used by
+// Logger.Ctx to add Log(s) from the context such as user id or tenant.
+func WithCtxExtractor(ex ...CtxExtractor) Option {
+	return func(o *options) {
+		o.extractors = append(o.extractors, ex...)
+	}
+}
+
+// WithCaller add the caller 'dir/file.go:line' as 'caller' and the function
+// name as 'function' to every log entry.
+func WithCaller() Option {
+	return func(o *options) {
+		o.caller = true
+	}
+}
+
+// WithCallerSkip increase the number of callers skipped by caller annotation.
+// Useful when the Logger is wrapped by another function, so the reported
+// caller is the real call site instead of the wrapper.
+func WithCallerSkip(skip int) Option {
+	return func(o *options) {
+		o.callerSkip += skip
+	}
+}
+
+// WithStacktrace attach the stack trace as 'stacktrace' to every log entry at
+// given lvl and above such as ErrorLevel.
+func WithStacktrace(lvl Level) Option {
+	return func(o *options) {
+		o.stack = true
+		o.stackLvl = lvl
+	}
+}
+
+// samplingFor return the sampling config that should be used by given w or
+// nil if there is none.
+func (o *options) samplingFor(w Writer) *SamplingConfig {
+	var cnf *SamplingConfig
+	for i, s := range o.sampling {
+		if matchWriter(s.wr, w) {
+			cnf = &o.sampling[i].cnf
+		}
+	}
+	return cnf
+}
+
+// redactorFor return the redactor that should be used by given w or nil if
+// there is none.
+func (o *options) redactorFor(w Writer) *redactor {
+	var policies []RedactPolicy
+	for _, r := range o.redaction {
+		if matchWriter(r.wr, w) {
+			policies = append(policies, r.policy)
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return newRedactor(policies...)
+}
+
+// encoderFor return the Encoder that should be used by given w or nil if the
+// backend JSON encoding should be used as is.
+func (o *options) encoderFor(w Writer) *Encoder {
+	if readsJSON(w) {
+		return nil
+	}
+	var enc *Encoder
+	for i, e := range o.encoders {
+		if matchWriter(e.wr, w) {
+			enc = &o.encoders[i].enc
+		}
+	}
+	if enc != nil {
+		return enc
+	}
+	// the wrapper such as RoutedWriter may wrap a Writer that declare its own
+	// Encoder
+	for ww := w; ww != nil; {
+		if ep, ok := ww.(EncoderProvider); ok {
+			e := ep.Encoder()
+			return &e
+		}
+		u, ok := ww.(interface{ Unwrap() Writer })
+		if !ok {
+			break
+		}
+		ww = u.Unwrap()
+	}
+	if w.Output() == CONSOLE {
+		e := defaultConsoleEncoder
+		return &e
+	}
+	return nil
+}
+
+// readsJSON return true if given w or any Writer it wraps read the level and
+// time from the default JSON encoding, so it can't use any other Encoder.
+func readsJSON(w Writer) bool {
+	for w != nil {
+		if out := w.Output(); out == PLATFORM || out == SYSLOG {
+			return true
+		}
+		u, ok := w.(interface{ Unwrap() Writer })
+		if !ok {
+			return false
+		}
+		w = u.Unwrap()
+	}
+	return false
+}
+
+// writerEncoder hold the Encoder for certain Writer(s).
+type writerEncoder struct {
+	enc Encoder
+	wr  []Writer
+}
+
+// matchWriter return true if given w is one of given wr or wr is empty which
+// means match all Writer(s).
+func matchWriter(wr []Writer, w Writer) bool {
+	if len(wr) == 0 {
+		return true
+	}
+	for _, ww := range wr {
+		if ww == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_SamplingFor(t *testing.T) {
+	cns := NewConsoleWriter(DebugLevel)
+	fl := NewFileWriter(DebugLevel, nil)
+	pl := NewPlatformWriter(DebugLevel, nil)
+
+	o := newOptions(
+		WithSampling(SamplingConfig{First: 1}),
+		WithSampling(SamplingConfig{First: 2}, fl),
+	)
+	assert.Equal(t, &SamplingConfig{First: 1}, o.samplingFor(cns))
+	assert.Equal(t, &SamplingConfig{First: 2}, o.samplingFor(fl))
+
+	o = newOptions(WithSampling(SamplingConfig{First: 3}, cns))
+	assert.Equal(t, &SamplingConfig{First: 3}, o.samplingFor(cns))
+	assert.Nil(t, o.samplingFor(pl))
+}
+
+func TestMatchWriter(t *testing.T) {
+	cns := NewConsoleWriter(DebugLevel)
+	pl := NewPlatformWriter(DebugLevel, nil)
+
+	assert.True(t, matchWriter(nil, cns))
+	assert.True(t, matchWriter([]Writer{pl, cns}, cns))
+	assert.False(t, matchWriter([]Writer{pl}, cns))
+}
@@ -0,0 +1,434 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// OTLPEncoding define the OTLP/HTTP request body encoding.
+type OTLPEncoding int8
+
+const (
+	// OTLPProtobuf binary protobuf encoding.
+	OTLPProtobuf OTLPEncoding = iota
+	// OTLPJSON JSON protobuf encoding.
+	OTLPJSON
+)
+
+// otlpScopeName the instrumentation scope name of every exported log record.
+const otlpScopeName = "github.com/mdanialr/api-pkg-go/log"
+
+// NewOTLPWriter return Writer implementer that export logs in batch as OTLP
+// LogRecords to OpenTelemetry Collector over OTLP/HTTP by given Config.OTLP
+// and set given lvl as the log Level. The severity is mapped from the Level,
+// the body is taken from the message, the trace_id and span_id are promoted
+// to the trace context, and the rest of the fields become the attributes.
+func NewOTLPWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	o := &otlpOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.OTLP}
+	// set default value
+	if o.cnf.URL == "" {
+		o.cnf.URL = "http://localhost:4318/v1/logs"
+	}
+	if o.cnf.Client == nil {
+		o.cnf.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if o.cnf.ServiceName != "" {
+		o.resource = append(o.resource, otlpKeyValue{key: "service.name", val: otlpValue{kind: otlpString, s: o.cnf.ServiceName}})
+	}
+	for k, v := range o.cnf.Resource {
+		o.resource = append(o.resource, otlpKeyValue{key: k, val: otlpValue{kind: otlpString, s: v}})
+	}
+	o.bt = newBatcher(o.cnf.Batch, "otlp-"+shortHash(o.cnf.URL), o.send, stampedEntry)
+	return o
+}
+
+type otlpOutput struct {
+	lvl      *AtomicLevel
+	cnf      OTLPConfig
+	resource []otlpKeyValue
+	bt       *batcher
+}
+
+// Write implement io.Writer. The current time is queued along given p, so
+// the record keep its original time even when it's spilled to disk.
+func (o *otlpOutput) Write(p []byte) (int, error) {
+	ts := strconv.FormatInt(time.Now().UnixNano(), 10)
+	o.bt.add(append([]byte(ts+" "), bytes.TrimSpace(p)...))
+	return len(p), nil
+}
+
+// send export given lines as a single request.
+func (o *otlpOutput) send(ctx context.Context, lines [][]byte) error {
+	records := make([]otlpRecord, 0, len(lines))
+	for _, l := range lines {
+		ts, line, _ := bytes.Cut(l, []byte{' '})
+		nsec, _ := strconv.ParseUint(string(ts), 10, 64)
+		records = append(records, newOTLPRecord(nsec, line))
+	}
+
+	var body []byte
+	contentType := "application/x-protobuf"
+	if o.cnf.Encoding == OTLPJSON {
+		contentType = "application/json"
+		b, err := json.Marshal(otlpJSONRequest(o.resource, records))
+		if err != nil {
+			return &permanentError{err}
+		}
+		body = b
+	} else {
+		body = appendOTLPRequest(nil, o.resource, records)
+	}
+
+	req, err := newBatchRequest(ctx, o.cnf.URL, body, o.cnf.Gzip)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range o.cnf.Headers {
+		req.Header.Set(k, v)
+	}
+	return doBatchRequest(o.cnf.Client, req)
+}
+
+func (o *otlpOutput) Writer() io.Writer             { return o }
+func (o *otlpOutput) Output() Output                { return OTLP }
+func (o *otlpOutput) Level() Level                  { return o.lvl.Level() }
+func (o *otlpOutput) SetLevel(lvl Level)            { o.lvl.SetLevel(lvl) }
+func (o *otlpOutput) Wait(_ time.Duration)          {}
+func (o *otlpOutput) Flush(dur time.Duration)       { o.bt.flush(dur) }
+func (o *otlpOutput) LastError() (time.Time, error) { return o.bt.lastError() }
+
+// addDropHook implement dropReporter.
+func (o *otlpOutput) addDropHook(key any, fn func(lines [][]byte, err error)) {
+	o.bt.addDropHook(key, fn)
+}
+
+// otlpKind the kind of OTLP AnyValue.
+type otlpKind int8
+
+const (
+	otlpString otlpKind = iota
+	otlpBool
+	otlpInt
+	otlpDouble
+	otlpArray
+	otlpKVList
+)
+
+// otlpValue OTLP AnyValue.
+type otlpValue struct {
+	kind otlpKind
+	s    string
+	b    bool
+	i    int64
+	d    float64
+	arr  []otlpValue
+	kv   []otlpKeyValue
+}
+
+// otlpKeyValue OTLP KeyValue.
+type otlpKeyValue struct {
+	key string
+	val otlpValue
+}
+
+// otlpRecord OTLP LogRecord.
+type otlpRecord struct {
+	time    uint64
+	sevNum  int32
+	sevText string
+	body    string
+	attrs   []otlpKeyValue
+	traceID []byte
+	spanID  []byte
+	// observed when the record is written
+	observed uint64
+}
+
+// newOTLPRecord return the OTLP LogRecord of given JSON encoded log line that
+// is written at given nsec.
+func newOTLPRecord(nsec uint64, line []byte) otlpRecord {
+	rec := otlpRecord{time: nsec, observed: nsec, sevNum: otlpSeverity(InfoLevel), sevText: InfoLevel.String()}
+	members, err := splitJSONObject(line)
+	if err != nil {
+		rec.body = string(line)
+		return rec
+	}
+
+	for _, m := range members {
+		switch m.key {
+		case "level":
+			lvl := ParseLevel(jsonString(m.val))
+			rec.sevNum, rec.sevText = otlpSeverity(lvl), lvl.String()
+		case "time":
+			rec.time = uint64(jsonTime(m.val, time.Unix(0, int64(nsec))).UnixNano())
+		case "msg":
+			rec.body = jsonString(m.val)
+		case "trace_id", "span_id":
+			// OTLP reject the whole request if the id is not 16 or 8 bytes
+			size := 16
+			if m.key == "span_id" {
+				size = 8
+			}
+			id, err := hex.DecodeString(jsonString(m.val))
+			if err != nil || len(id) != size {
+				rec.attrs = append(rec.attrs, otlpKeyValue{key: m.key, val: toOTLPValue(m.val)})
+				continue
+			}
+			if m.key == "trace_id" {
+				rec.traceID = id
+			} else {
+				rec.spanID = id
+			}
+		default:
+			rec.attrs = append(rec.attrs, otlpKeyValue{key: m.key, val: toOTLPValue(m.val)})
+		}
+	}
+	return rec
+}
+
+// toOTLPValue transform given raw JSON value to OTLP AnyValue.
+func toOTLPValue(raw json.RawMessage) otlpValue {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return otlpValue{kind: otlpString, s: string(raw)}
+	}
+	if m, ok := v.(map[string]any); ok {
+		// keep the order of the object keys
+		members, err := splitJSONObject(raw)
+		if err != nil {
+			return anyToOTLPValue(m)
+		}
+		kv := make([]otlpKeyValue, 0, len(members))
+		for _, mb := range members {
+			kv = append(kv, otlpKeyValue{key: mb.key, val: toOTLPValue(mb.val)})
+		}
+		return otlpValue{kind: otlpKVList, kv: kv}
+	}
+	return anyToOTLPValue(v)
+}
+
+// anyToOTLPValue transform given v that is decoded from JSON using
+// json.Decoder.UseNumber to OTLP AnyValue.
+func anyToOTLPValue(v any) otlpValue {
+	switch vv := v.(type) {
+	case bool:
+		return otlpValue{kind: otlpBool, b: vv}
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return otlpValue{kind: otlpInt, i: i}
+		}
+		f, _ := vv.Float64()
+		return otlpValue{kind: otlpDouble, d: f}
+	case string:
+		return otlpValue{kind: otlpString, s: vv}
+	case []any:
+		arr := make([]otlpValue, 0, len(vv))
+		for _, e := range vv {
+			arr = append(arr, anyToOTLPValue(e))
+		}
+		return otlpValue{kind: otlpArray, arr: arr}
+	case map[string]any:
+		kv := make([]otlpKeyValue, 0, len(vv))
+		for k, e := range vv {
+			kv = append(kv, otlpKeyValue{key: k, val: anyToOTLPValue(e)})
+		}
+		return otlpValue{kind: otlpKVList, kv: kv}
+	}
+	// null is represented as empty AnyValue, use empty string instead
+	return otlpValue{kind: otlpString}
+}
+
+// otlpSeverity map given Level to OTLP SeverityNumber.
+func otlpSeverity(lvl Level) int32 {
+	switch lvl {
+	case TraceLevel:
+		return 1
+	case DebugLevel:
+		return 5
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	case PanicLevel:
+		return 21
+	case FatalLevel:
+		return 24
+	}
+	return 9
+}
+
+// appendOTLPRequest append the protobuf encoding of ExportLogsServiceRequest
+// that hold given resource attributes and records to given b.
+func appendOTLPRequest(b []byte, resource []otlpKeyValue, records []otlpRecord) []byte {
+	// ExportLogsServiceRequest.resource_logs
+	return appendProtoMessage(b, 1, func(b []byte) []byte {
+		// ResourceLogs.resource
+		b = appendProtoMessage(b, 1, func(b []byte) []byte {
+			for _, kv := range resource {
+				b = appendProtoMessage(b, 1, kv.appendProto)
+			}
+			return b
+		})
+		// ResourceLogs.scope_logs
+		return appendProtoMessage(b, 2, func(b []byte) []byte {
+			b = appendProtoMessage(b, 1, func(b []byte) []byte {
+				b = protowire.AppendTag(b, 1, protowire.BytesType)
+				return protowire.AppendString(b, otlpScopeName)
+			})
+			for _, r := range records {
+				b = appendProtoMessage(b, 2, r.appendProto)
+			}
+			return b
+		})
+	})
+}
+
+// appendProto append the protobuf encoding of the LogRecord to given b.
+func (r otlpRecord) appendProto(b []byte) []byte {
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, r.time)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(r.sevNum))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, r.sevText)
+	b = appendProtoMessage(b, 5, otlpValue{kind: otlpString, s: r.body}.appendProto)
+	for _, kv := range r.attrs {
+		b = appendProtoMessage(b, 6, kv.appendProto)
+	}
+	if len(r.traceID) > 0 {
+		b = protowire.AppendTag(b, 9, protowire.BytesType)
+		b = protowire.AppendBytes(b, r.traceID)
+	}
+	if len(r.spanID) > 0 {
+		b = protowire.AppendTag(b, 10, protowire.BytesType)
+		b = protowire.AppendBytes(b, r.spanID)
+	}
+	b = protowire.AppendTag(b, 11, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, r.observed)
+}
+
+// appendProto append the protobuf encoding of the KeyValue to given b.
+func (kv otlpKeyValue) appendProto(b []byte) []byte {
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, kv.key)
+	return appendProtoMessage(b, 2, kv.val.appendProto)
+}
+
+// appendProto append the protobuf encoding of the AnyValue to given b.
+func (v otlpValue) appendProto(b []byte) []byte {
+	switch v.kind {
+	case otlpBool:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v.b))
+	case otlpInt:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(v.i))
+	case otlpDouble:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(v.d))
+	case otlpArray:
+		return appendProtoMessage(b, 5, func(b []byte) []byte {
+			for _, e := range v.arr {
+				b = appendProtoMessage(b, 1, e.appendProto)
+			}
+			return b
+		})
+	case otlpKVList:
+		return appendProtoMessage(b, 6, func(b []byte) []byte {
+			for _, kv := range v.kv {
+				b = appendProtoMessage(b, 1, kv.appendProto)
+			}
+			return b
+		})
+	}
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendString(b, v.s)
+}
+
+// appendProtoMessage append the embedded message with given field number that
+// is encoded by given fn to given b.
+func appendProtoMessage(b []byte, num protowire.Number, fn func([]byte) []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, fn(nil))
+}
+
+// otlpJSONRequest return the OTLP/HTTP JSON representation of
+// ExportLogsServiceRequest that hold given resource attributes and records.
+func otlpJSONRequest(resource []otlpKeyValue, records []otlpRecord) map[string]any {
+	logs := make([]any, 0, len(records))
+	for _, r := range records {
+		rec := map[string]any{
+			"timeUnixNano":         strconv.FormatUint(r.time, 10),
+			"observedTimeUnixNano": strconv.FormatUint(r.observed, 10),
+			"severityNumber":       r.sevNum,
+			"severityText":         r.sevText,
+			"body":                 otlpValue{kind: otlpString, s: r.body}.json(),
+			"attributes":           otlpJSONKeyValues(r.attrs),
+		}
+		if len(r.traceID) > 0 {
+			rec["traceId"] = hex.EncodeToString(r.traceID)
+		}
+		if len(r.spanID) > 0 {
+			rec["spanId"] = hex.EncodeToString(r.spanID)
+		}
+		logs = append(logs, rec)
+	}
+
+	return map[string]any{
+		"resourceLogs": []any{map[string]any{
+			"resource": map[string]any{"attributes": otlpJSONKeyValues(resource)},
+			"scopeLogs": []any{map[string]any{
+				"scope":      map[string]any{"name": otlpScopeName},
+				"logRecords": logs,
+			}},
+		}},
+	}
+}
+
+// otlpJSONKeyValues return the OTLP/HTTP JSON representation of given
+// KeyValue(s).
+func otlpJSONKeyValues(kvs []otlpKeyValue) []any {
+	res := make([]any, 0, len(kvs))
+	for _, kv := range kvs {
+		res = append(res, map[string]any{"key": kv.key, "value": kv.val.json()})
+	}
+	return res
+}
+
+// json return the OTLP/HTTP JSON representation of the AnyValue.
+func (v otlpValue) json() map[string]any {
+	switch v.kind {
+	case otlpBool:
+		return map[string]any{"boolValue": v.b}
+	case otlpInt:
+		// int64 is encoded as string in protobuf JSON
+		return map[string]any{"intValue": strconv.FormatInt(v.i, 10)}
+	case otlpDouble:
+		return map[string]any{"doubleValue": v.d}
+	case otlpArray:
+		values := make([]any, 0, len(v.arr))
+		for _, e := range v.arr {
+			values = append(values, e.json())
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	case otlpKVList:
+		return map[string]any{"kvlistValue": map[string]any{"values": otlpJSONKeyValues(v.kv)}}
+	}
+	return map[string]any{"stringValue": v.s}
+}
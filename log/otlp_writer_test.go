@@ -0,0 +1,222 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoFields decode every field of given protobuf encoded message, the value
+// is []byte for length-delimited field and uint64 for the rest.
+func protoFields(t *testing.T, b []byte) map[protowire.Number][]any {
+	fields := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		var v any
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		fields[num] = append(fields[num], v)
+	}
+	return fields
+}
+
+// protoMessage decode the embedded message at given path of field numbers.
+func protoMessage(t *testing.T, b []byte, path ...protowire.Number) map[protowire.Number][]any {
+	fields := protoFields(t, b)
+	for _, num := range path {
+		require.NotEmpty(t, fields[num])
+		fields = protoFields(t, fields[num][0].([]byte))
+	}
+	return fields
+}
+
+func TestNewOTLPWriter(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	testCases := []struct {
+		name        string
+		encoding    OTLPEncoding
+		contentType string
+		assertBody  func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "Protobuf",
+			encoding:    OTLPProtobuf,
+			contentType: "application/x-protobuf",
+			assertBody: func(t *testing.T, body []byte) {
+				// ExportLogsServiceRequest.resource_logs.resource
+				res := protoMessage(t, body, 1, 1)
+				kv := protoFields(t, res[1][0].([]byte))
+				assert.Equal(t, "service.name", string(kv[1][0].([]byte)))
+				assert.Equal(t, "kpm", string(protoFields(t, kv[2][0].([]byte))[1][0].([]byte)))
+
+				// ExportLogsServiceRequest.resource_logs.scope_logs
+				scope := protoMessage(t, body, 1, 2)
+				require.Len(t, scope[2], 2)
+				rec := protoFields(t, scope[2][1].([]byte))
+				assert.NotZero(t, rec[1][0])
+				assert.Equal(t, uint64(17), rec[2][0])
+				assert.Equal(t, "ERROR", string(rec[3][0].([]byte)))
+				assert.Equal(t, "second", string(protoFields(t, rec[5][0].([]byte))[1][0].([]byte)))
+				assert.Equal(t, sc.TraceID().String(), trace.TraceID(rec[9][0].([]byte)).String())
+				assert.Equal(t, sc.SpanID().String(), trace.SpanID(rec[10][0].([]byte)).String())
+
+				// the attributes
+				require.Len(t, rec[6], 2)
+				num := protoFields(t, rec[6][0].([]byte))
+				assert.Equal(t, "scale", string(num[1][0].([]byte)))
+				assert.Equal(t, 1.5, math.Float64frombits(protoFields(t, num[2][0].([]byte))[4][0].(uint64)))
+				usr := protoFields(t, rec[6][1].([]byte))
+				assert.Equal(t, "user", string(usr[1][0].([]byte)))
+				// kvlist_value.values[0]
+				id := protoMessage(t, usr[2][0].([]byte), 6, 1)
+				assert.Equal(t, "id", string(id[1][0].([]byte)))
+				assert.Equal(t, uint64(7), protoFields(t, id[2][0].([]byte))[3][0])
+			},
+		},
+		{
+			name:        "JSON",
+			encoding:    OTLPJSON,
+			contentType: "application/json",
+			assertBody: func(t *testing.T, body []byte) {
+				var req struct {
+					ResourceLogs []struct {
+						Resource struct {
+							Attributes []map[string]any `json:"attributes"`
+						} `json:"resource"`
+						ScopeLogs []struct {
+							Scope      map[string]string `json:"scope"`
+							LogRecords []map[string]any  `json:"logRecords"`
+						} `json:"scopeLogs"`
+					} `json:"resourceLogs"`
+				}
+				require.NoError(t, json.Unmarshal(body, &req))
+				require.Len(t, req.ResourceLogs, 1)
+				rl := req.ResourceLogs[0]
+				assert.Contains(t, rl.Resource.Attributes, map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "kpm"}})
+				assert.Equal(t, otlpScopeName, rl.ScopeLogs[0].Scope["name"])
+
+				require.Len(t, rl.ScopeLogs[0].LogRecords, 2)
+				inf := rl.ScopeLogs[0].LogRecords[0]
+				assert.Equal(t, float64(9), inf["severityNumber"])
+				assert.Equal(t, "INFO", inf["severityText"])
+				assert.Equal(t, map[string]any{"stringValue": "first"}, inf["body"])
+				assert.Equal(t, []any{map[string]any{"key": "ok", "value": map[string]any{"boolValue": true}}}, inf["attributes"])
+				assert.NotContains(t, inf, "traceId")
+
+				err := rl.ScopeLogs[0].LogRecords[1]
+				assert.Equal(t, sc.TraceID().String(), err["traceId"])
+				assert.Equal(t, sc.SpanID().String(), err["spanId"])
+				assert.Equal(t, []any{
+					map[string]any{"key": "scale", "value": map[string]any{"doubleValue": 1.5}},
+					map[string]any{"key": "user", "value": map[string]any{"kvlistValue": map[string]any{"values": []any{
+						map[string]any{"key": "id", "value": map[string]any{"intValue": "7"}},
+					}}}},
+				}, err["attributes"])
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				body []byte
+				req  *http.Request
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req = r
+				body, _ = io.ReadAll(r.Body)
+			}))
+			defer srv.Close()
+
+			cnf := NewConfig(
+				WithOTLPURL(srv.URL+"/v1/logs"),
+				WithOTLPEncoding(tc.encoding),
+				WithOTLPHeader("X-Api-Key", "secret"),
+				WithOTLPServiceName("kpm"),
+				WithOTLPResource("deployment.environment", "prod"),
+				WithOTLPBatch(BatchConfig{Size: 10, Interval: time.Hour}),
+			)
+			ow := NewOTLPWriter(DebugLevel, cnf)
+			assert.Equal(t, OTLP, ow.Output())
+
+			wr := NewZapLogger(ow)
+			wr.Init(time.Second)
+			wr.Inf("first", Bool("ok", true))
+			wr.Ctx(ctx).Err("second", Float("scale", 1.5), Namespace("user", Num("id", 7)))
+			wr.Flush(time.Second)
+
+			require.NotNil(t, req)
+			assert.Equal(t, "/v1/logs", req.URL.Path)
+			assert.Equal(t, tc.contentType, req.Header.Get("Content-Type"))
+			assert.Equal(t, "secret", req.Header.Get("X-Api-Key"))
+			tc.assertBody(t, body)
+		})
+	}
+}
+
+func TestNewOTLPRecord(t *testing.T) {
+	rec := newOTLPRecord(1, []byte(`{"time":"2023-09-22T13:38:39+07:00","msg":"ok","trace_id":"0102030405060708090a0b0c0d0e0f10","span_id":"0102030405060708"}`))
+	assert.Equal(t, uint64(1695364719000000000), rec.time)
+	assert.Equal(t, uint64(1), rec.observed)
+	assert.Equal(t, trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, trace.TraceID(rec.traceID))
+	assert.Equal(t, trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, trace.SpanID(rec.spanID))
+	assert.Empty(t, rec.attrs)
+
+	// the id of the wrong length is kept as the attribute
+	rec = newOTLPRecord(1, []byte(`{"msg":"ok","trace_id":"abcd","span_id":"0102030405060708090a"}`))
+	assert.Nil(t, rec.traceID)
+	assert.Nil(t, rec.spanID)
+	assert.Equal(t, []otlpKeyValue{
+		{key: "trace_id", val: otlpValue{kind: otlpString, s: "abcd"}},
+		{key: "span_id", val: otlpValue{kind: otlpString, s: "0102030405060708090a"}},
+	}, rec.attrs)
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	testCases := []struct {
+		sample Level
+		expect int32
+	}{
+		{sample: TraceLevel, expect: 1},
+		{sample: DebugLevel, expect: 5},
+		{sample: InfoLevel, expect: 9},
+		{sample: WarnLevel, expect: 13},
+		{sample: ErrorLevel, expect: 17},
+		{sample: PanicLevel, expect: 21},
+		{sample: FatalLevel, expect: 24},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sample.String(), func(t *testing.T) {
+			assert.Equal(t, tc.expect, otlpSeverity(tc.sample))
+		})
+	}
+}
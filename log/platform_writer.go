@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+)
+
+// NewPlatformWriter return Writer implementer that write logs as single-line
+// JSON to os.Stdout which is the expected format for container platform such
+// as Kubernetes. Set Config.Platform.Stderr to write ErrorLevel and above to
+// os.Stderr instead.
+func NewPlatformWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	p := &platformOutput{lvl: NewAtomicLevel(lvl), out: os.Stdout}
+	if cnf.Platform.Stderr {
+		p.err = os.Stderr
+	}
+	return p
+}
+
+type platformOutput struct {
+	out io.Writer
+	err io.Writer
+	lvl *AtomicLevel
+}
+
+// Write implement io.Writer.
+func (p *platformOutput) Write(b []byte) (int, error) {
+	if lvl := levelOf(b); p.err != nil && lvl >= ErrorLevel && lvl.Valid() {
+		return p.err.Write(b)
+	}
+	return p.out.Write(b)
+}
+func (p *platformOutput) Writer() io.Writer     { return p }
+func (p *platformOutput) Output() Output        { return PLATFORM }
+func (p *platformOutput) Level() Level          { return p.lvl.Level() }
+func (p *platformOutput) SetLevel(lvl Level)    { p.lvl.SetLevel(lvl) }
+func (p *platformOutput) Wait(_ time.Duration)  {}
+func (p *platformOutput) Flush(_ time.Duration) {}
+
+// levelKey the JSON key prefix that hold the log level in every encoded log
+// line.
+var levelKey = []byte(`"level":"`)
+
+// levelOf grab the log Level from given JSON encoded log line. Return
+// InvalidLevel if the level could not be found.
+func levelOf(b []byte) Level {
+	i := bytes.Index(b, levelKey)
+	if i < 0 {
+		return InvalidLevel
+	}
+	b = b[i+len(levelKey):]
+	if j := bytes.IndexByte(b, '"'); j >= 0 {
+		return ParseLevel(string(b[:j]))
+	}
+	return InvalidLevel
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPlatformWriter(t *testing.T) {
+	t.Run("Should return the expected value in each Writer implementation", func(t *testing.T) {
+		wr := NewPlatformWriter(InfoLevel, nil)
+		assert.IsType(t, &platformOutput{}, wr.Writer())
+		assert.Equal(t, PLATFORM, wr.Output())
+		assert.Equal(t, InfoLevel, wr.Level())
+		assert.Equal(t, os.Stdout, wr.(*platformOutput).out)
+		assert.Nil(t, wr.(*platformOutput).err)
+
+		// just run
+		wr.Wait(-1)
+		wr.Flush(-1)
+	})
+	t.Run("Should use stderr when enabled in config", func(t *testing.T) {
+		wr := NewPlatformWriter(InfoLevel, NewConfig(WithPlatformStderr(true)))
+		assert.Equal(t, os.Stderr, wr.(*platformOutput).err)
+	})
+}
+
+func TestPlatformOutput_Write(t *testing.T) {
+	t.Run("Should write error and above to stderr if set", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		p := &platformOutput{out: &out, err: &errOut}
+
+		p.Write([]byte(`{"level":"INFO","msg":"info"}` + "\n"))
+		p.Write([]byte(`{"level":"ERROR","msg":"error"}` + "\n"))
+
+		assert.Equal(t, `{"level":"INFO","msg":"info"}`+"\n", out.String())
+		assert.Equal(t, `{"level":"ERROR","msg":"error"}`+"\n", errOut.String())
+	})
+	t.Run("Should write everything to stdout if stderr is not set", func(t *testing.T) {
+		var out bytes.Buffer
+		p := &platformOutput{out: &out}
+
+		p.Write([]byte(`{"level":"ERROR","msg":"error"}` + "\n"))
+		assert.Contains(t, out.String(), `"msg":"error"`)
+	})
+}
+
+func TestLevelOf(t *testing.T) {
+	assert.Equal(t, WarnLevel, levelOf([]byte(`{"time":"now","level":"WARN","msg":"hi"}`)))
+	assert.Equal(t, InvalidLevel, levelOf([]byte(`{"msg":"hi"}`)))
+	assert.Equal(t, InvalidLevel, levelOf([]byte(`{"level":"ERR`)))
+}
+
+func TestPlatformWriterWithLogger(t *testing.T) {
+	for name, fn := range map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger} {
+		t.Run(name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			p := &platformOutput{lvl: NewAtomicLevel(DebugLevel), out: &out, err: &errOut}
+			wr := fn(p)
+			wr.Init(time.Microsecond)
+
+			wr.Inf("info log", String("hello", "world"))
+			wr.Err("error log")
+
+			assert.Contains(t, out.String(), `"msg":"info log"`)
+			assert.Contains(t, out.String(), `"hello":"world"`)
+			assert.NotContains(t, out.String(), "error log")
+			assert.Contains(t, errOut.String(), `"msg":"error log"`)
+			// single-line JSON without any ANSI colors
+			assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("\n")))
+			assert.NotContains(t, out.String(), "\x1b[")
+			wr.Flush(time.Microsecond)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package log
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RedactMode define how the sensitive value should be redacted.
+type RedactMode int8
+
+const (
+	// RedactFull replace the whole value with "[REDACTED]".
+	RedactFull RedactMode = iota
+	// RedactPartial mask all but the last 4 characters of the value, useful
+	// for value such as phone or card number that still need to be
+	// recognizable.
+	RedactPartial
+	// RedactHash replace the value with its SHA-256 hex digest, so the same
+	// value still can be correlated between log entries.
+	RedactHash
+)
+
+// redactedValue the replacement value used by RedactFull.
+const redactedValue = "[REDACTED]"
+
+// RedactPolicy define which fields are sensitive and how they should be
+// redacted.
+type RedactPolicy struct {
+	// Keys the field key names that are redacted wherever they are, including
+	// inside nested map, struct, slice and Group. Case-insensitive.
+	Keys []string
+	// Paths the dot separated glob paths of the fields that are redacted,
+	// where '*' match exactly one key or slice index. Example
+	// 'user.*.ktp_no' or 'payload.cards.*.number'.
+	Paths []string
+	// Mode how the matched fields are redacted.
+	Mode RedactMode
+}
+
+// writerRedaction RedactPolicy for certain Writer(s).
+type writerRedaction struct {
+	policy RedactPolicy
+	wr     []Writer
+}
+
+// newRedactor return new redactor that apply all given policies.
+func newRedactor(policies ...RedactPolicy) *redactor {
+	r := &redactor{keys: make(map[string]RedactMode)}
+	for _, p := range policies {
+		for _, k := range p.Keys {
+			r.keys[strings.ToLower(k)] = p.Mode
+		}
+		for _, pt := range p.Paths {
+			r.paths = append(r.paths, redactPath{pattern: strings.ReplaceAll(pt, ".", "/"), mode: p.Mode})
+		}
+	}
+	return r
+}
+
+// redactor redact the sensitive value based on the RedactPolicy. This is
+// shared by both zap and slog backend, so they behave exactly the same.
+type redactor struct {
+	keys  map[string]RedactMode
+	paths []redactPath
+}
+
+// redactPath glob pattern that use '/' as the separator.
+type redactPath struct {
+	pattern string
+	mode    RedactMode
+}
+
+// match return the RedactMode if the field at given path should be redacted.
+func (r *redactor) match(keys []string) (RedactMode, bool) {
+	if m, ok := r.keys[strings.ToLower(keys[len(keys)-1])]; ok {
+		return m, true
+	}
+	if len(r.paths) > 0 {
+		p := strings.Join(keys, "/")
+		for _, rp := range r.paths {
+			if ok, _ := path.Match(rp.pattern, p); ok {
+				return rp.mode, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// redact return the redacted copy of given v that is located at given path
+// and true if there is any redacted value. Given v is never modified.
+func (r *redactor) redact(keys []string, v any) (any, bool) {
+	if m, ok := r.match(keys); ok {
+		return mask(m, v), true
+	}
+
+	switch vv := normalize(v).(type) {
+	case map[string]any:
+		var res map[string]any
+		for k, val := range vv {
+			nv, changed := r.redact(append(keys, k), val)
+			if changed && res == nil {
+				// copy on write
+				res = make(map[string]any, len(vv))
+				for kk, vvv := range vv {
+					res[kk] = vvv
+				}
+			}
+			if changed {
+				res[k] = nv
+			}
+		}
+		if res != nil {
+			return res, true
+		}
+	case []any:
+		var res []any
+		for i, val := range vv {
+			nv, changed := r.redact(append(keys, strconv.Itoa(i)), val)
+			if changed && res == nil {
+				res = make([]any, len(vv))
+				copy(res, vv)
+			}
+			if changed {
+				res[i] = nv
+			}
+		}
+		if res != nil {
+			return res, true
+		}
+	}
+	return v, false
+}
+
+// normalize transform given v to either map[string]any, []any or just return
+// it back if it's a primitive value. Error and fmt.Stringer are transformed to
+// their string the same way as they are logged. Map, struct and slice are
+// transformed through their JSON representation, so the keys are the same as
+// the encoded log entry, and the numbers are kept as json.Number, so the
+// large number such as id or phone number keep its digits.
+func normalize(v any) any {
+	switch vv := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, json.Number, map[string]any, []any:
+		return vv
+	case error:
+		return vv.Error()
+	case fmt.Stringer:
+		return vv.String()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var res any
+	if err = dec.Decode(&res); err != nil {
+		return v
+	}
+	return res
+}
+
+// mask redact given v using given RedactMode.
+func mask(m RedactMode, v any) string {
+	var s string
+	switch vv := normalize(v).(type) {
+	case string:
+		s = vv
+	case map[string]any, []any:
+		b, _ := marshalJSON(vv)
+		s = string(b)
+	default:
+		s = fmt.Sprint(vv)
+	}
+
+	switch m {
+	case RedactPartial:
+		rs := []rune(s)
+		if len(rs) <= 4 {
+			return strings.Repeat("*", len(rs))
+		}
+		return strings.Repeat("*", len(rs)-4) + string(rs[len(rs)-4:])
+	case RedactHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return redactedValue
+}
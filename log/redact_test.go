@@ -0,0 +1,165 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMask(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mode   RedactMode
+		sample any
+		expect string
+	}{
+		{
+			name:   "Full mask",
+			mode:   RedactFull,
+			sample: "secret",
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "Partial mask keep the last 4 characters",
+			mode:   RedactPartial,
+			sample: "081234567890",
+			expect: "********7890",
+		},
+		{
+			name:   "Partial mask short value",
+			mode:   RedactPartial,
+			sample: 1234,
+			expect: "****",
+		},
+		{
+			name:   "Hash",
+			mode:   RedactHash,
+			sample: "secret",
+			expect: "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+		},
+		{
+			name:   "Error value",
+			mode:   RedactPartial,
+			sample: errors.New("oops!"),
+			expect: "*ops!",
+		},
+		{
+			name:   "Stringer value",
+			mode:   RedactPartial,
+			sample: time.Second,
+			expect: "**",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, mask(tc.mode, tc.sample))
+		})
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	rd := newRedactor(
+		RedactPolicy{Keys: []string{"Password", "token"}},
+		RedactPolicy{Paths: []string{"payload.user.*.ktp_no", "payload.cards.*", "cards.*"}, Mode: RedactPartial},
+	)
+	type profile struct {
+		KtpNo string `json:"ktp_no"`
+		Name  string `json:"name"`
+	}
+	sample := map[string]any{
+		"name":     "john",
+		"password": "secret",
+		"user": map[string]any{
+			"profile": profile{KtpNo: "3201234567890001", Name: "john"},
+			"ktp_no":  "not matched by path",
+		},
+		"cards":  []string{"4111111111111111"},
+		"nested": []any{map[string]any{"token": "abc"}},
+	}
+
+	res, changed := rd.redact([]string{"payload"}, sample)
+	require.True(t, changed)
+	assert.Equal(t, map[string]any{
+		"name":     "john",
+		"password": "[REDACTED]",
+		"user": map[string]any{
+			"profile": map[string]any{"ktp_no": "************0001", "name": "john"},
+			"ktp_no":  "not matched by path",
+		},
+		"cards":  []any{"************1111"},
+		"nested": []any{map[string]any{"token": "[REDACTED]"}},
+	}, res)
+	// given value is never modified
+	assert.Equal(t, "secret", sample["password"])
+
+	res, changed = rd.redact([]string{"cards"}, []string{"4111111111111111"})
+	require.True(t, changed)
+	assert.Equal(t, []any{"************1111"}, res)
+
+	res, changed = rd.redact([]string{"name"}, "john")
+	assert.False(t, changed)
+	assert.Equal(t, "john", res)
+
+	// the large number inside struct is not turned into float
+	res, changed = rd.redact([]string{"cards"}, []int64{4111111111111111})
+	require.True(t, changed)
+	assert.Equal(t, []any{"************1111"}, res)
+}
+
+func TestLoggerWithRedaction(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	type payload struct {
+		Phone string `json:"phone"`
+		Items []int  `json:"items"`
+	}
+	results := make(map[string][2][]loggedLog)
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			fl, flObs := NewObserverWriter(DebugLevel, FILE)
+			nr, nrObs := NewObserverWriter(DebugLevel, NEWRELIC)
+			wr := fn([]Writer{fl, nr},
+				WithRedaction(RedactPolicy{Keys: []string{"password", "token"}}),
+				WithRedaction(RedactPolicy{Keys: []string{"phone"}, Paths: []string{"req.ktp_no"}, Mode: RedactPartial}, nr),
+			)
+			wr.Init(time.Microsecond)
+
+			wr = wr.With(String("token", "abc"), String("app_env", "local"))
+			wr = wr.Group("req", String("ktp_no", "3201234567890001"), Num("password", 1234))
+			wr.Inf("info log", Any("payload", payload{Phone: "081234567890", Items: []int{1}}), String("phone", "081234567890"))
+
+			fls, nrs := flObs.TakeAll(), nrObs.TakeAll()
+			require.Len(t, fls, 1)
+			require.Len(t, nrs, 1)
+
+			// file writer only redact the global policy
+			assert.Equal(t, "[REDACTED]", fls[0].Get("token"))
+			assert.Equal(t, "local", fls[0].Get("app_env"))
+			assert.Equal(t, map[string]any{"ktp_no": "3201234567890001", "password": "[REDACTED]"}, fls[0].Get("req"))
+			assert.Equal(t, map[string]any{"phone": "081234567890", "items": []any{float64(1)}}, fls[0].Get("payload"))
+			assert.Equal(t, "081234567890", fls[0].Get("phone"))
+
+			// newrelic writer redact both
+			assert.Equal(t, "[REDACTED]", nrs[0].Get("token"))
+			assert.Equal(t, map[string]any{"ktp_no": "************0001", "password": "[REDACTED]"}, nrs[0].Get("req"))
+			assert.Equal(t, map[string]any{"phone": "********7890", "items": []any{float64(1)}}, nrs[0].Get("payload"))
+			assert.Equal(t, "********7890", nrs[0].Get("phone"))
+
+			results[name] = [2][]loggedLog{fls, nrs}
+		})
+	}
+	t.Run("Both backend should produce the same output", func(t *testing.T) {
+		for i := range results["zap"] {
+			zl, sl := results["zap"][i][0], results["slog"][i][0]
+			for _, k := range []string{"token", "app_env", "req", "payload", "phone"} {
+				assert.Equal(t, zl.Get(k), sl.Get(k), k)
+			}
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Entry the log entry that is given to the route filter before it's encoded.
+type Entry struct {
+	Level   Level
+	Message string
+	field   func(key string) (any, bool)
+}
+
+// Field return the value of the top-level field with given key, including the
+// one that is added through Logger.With. The value is the same type as given
+// to the Log constructor, except integer is int64 and error is its message.
+func (e Entry) Field(key string) (any, bool) {
+	if e.field == nil {
+		return nil, false
+	}
+	return e.field(key)
+}
+
+// RouteOpt an option signature for RoutedWriter.
+type RouteOpt func(*RoutedWriter)
+
+// WithRouteMaxLevel set the maximum Level that is written, so along with the
+// wrapped Writer Level it accept a level range such as WarnLevel to
+// ErrorLevel.
+func WithRouteMaxLevel(lvl Level) RouteOpt {
+	return func(r *RoutedWriter) {
+		r.max = lvl
+	}
+}
+
+// WithRouteFilter add given fn that decide whether the entry is written. Can
+// be applied multiple times and the entry is only written if all of them
+// return true.
+func WithRouteFilter(fn func(e Entry) bool) RouteOpt {
+	return func(r *RoutedWriter) {
+		r.filters = append(r.filters, fn)
+	}
+}
+
+// WithRouteField only write the entry that has the top-level field with given
+// key and val such as 'audit' and true.
+func WithRouteField(key string, val any) RouteOpt {
+	want := fmt.Sprint(val)
+	return WithRouteFilter(func(e Entry) bool {
+		v, ok := e.Field(key)
+		return ok && fmt.Sprint(v) == want
+	})
+}
+
+// NewRoutedWriter return RoutedWriter that wrap given w, so only the entries
+// within the level range that pass every filter are written to w. Both zap
+// and slog backend check it before the entry is encoded, so the discarded
+// entry cost nothing.
+func NewRoutedWriter(w Writer, opts ...RouteOpt) *RoutedWriter {
+	r := &RoutedWriter{wr: w, max: FatalLevel}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RoutedWriter Writer implementer that only write certain entries to the
+// wrapped Writer.
+type RoutedWriter struct {
+	wr      Writer
+	max     Level
+	filters []func(e Entry) bool
+}
+
+// enabled return true if given lvl is within the level range.
+func (r *RoutedWriter) enabled(lvl Level) bool {
+	return lvl >= r.wr.Level() && lvl <= r.max
+}
+
+// match return true if given e pass every filter.
+func (r *RoutedWriter) match(e Entry) bool {
+	for _, fn := range r.filters {
+		if !fn(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Unwrap return the wrapped Writer.
+func (r *RoutedWriter) Unwrap() Writer { return r.wr }
+
+func (r *RoutedWriter) Writer() io.Writer       { return r.wr.Writer() }
+func (r *RoutedWriter) Output() Output          { return r.wr.Output() }
+func (r *RoutedWriter) Level() Level            { return r.wr.Level() }
+func (r *RoutedWriter) Wait(dur time.Duration)  { r.wr.Wait(dur) }
+func (r *RoutedWriter) Flush(dur time.Duration) { r.wr.Flush(dur) }
+
+// SetLevel change the wrapped Writer Level if it implements LevelSetter.
+func (r *RoutedWriter) SetLevel(lvl Level) {
+	if ls, ok := r.wr.(LevelSetter); ok {
+		ls.SetLevel(lvl)
+	}
+}
+
+// routerOf return the RoutedWriter of given w by unwrapping it if needed, or
+// nil if there is none.
+func routerOf(w Writer) *RoutedWriter {
+	for w != nil {
+		if r, ok := w.(*RoutedWriter); ok {
+			return r
+		}
+		u, ok := w.(interface{ Unwrap() Writer })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+	return nil
+}
+
+// fieldValue normalize given field value, so it's the same regardless of the
+// backend.
+func fieldValue(v any) any {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
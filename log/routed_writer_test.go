@@ -0,0 +1,121 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStringer fmt.Stringer implementer that count how many times it's
+// encoded.
+type countingStringer struct {
+	n atomic.Int32
+}
+
+func (c *countingStringer) String() string {
+	c.n.Add(1)
+	return "encoded"
+}
+
+func TestNewRoutedWriter(t *testing.T) {
+	w, _ := NewObserverWriter(WarnLevel, FILE)
+	rt := NewRoutedWriter(w, WithRouteMaxLevel(ErrorLevel))
+
+	assert.Equal(t, FILE, rt.Output())
+	assert.Equal(t, WarnLevel, rt.Level())
+	assert.Equal(t, w, rt.Unwrap())
+	assert.True(t, rt.enabled(WarnLevel))
+	assert.True(t, rt.enabled(ErrorLevel))
+	assert.False(t, rt.enabled(InfoLevel))
+	assert.False(t, rt.enabled(PanicLevel))
+
+	rt.SetLevel(InfoLevel)
+	assert.Equal(t, InfoLevel, w.Level())
+	assert.True(t, rt.enabled(InfoLevel))
+
+	// found through the other wrapper
+	assert.Equal(t, rt, routerOf(NewAsyncWriter(rt)))
+	assert.Nil(t, routerOf(w))
+}
+
+func TestRoutedWriter_match(t *testing.T) {
+	fields := map[string]any{"audit": true, "user": "kpm", "id": int64(7)}
+	e := Entry{Level: InfoLevel, Message: "login", field: func(key string) (any, bool) {
+		v, ok := fields[key]
+		return v, ok
+	}}
+
+	testCases := []struct {
+		name string
+		opts []RouteOpt
+		want bool
+	}{
+		{name: "no filter", want: true},
+		{name: "bool field", opts: []RouteOpt{WithRouteField("audit", true)}, want: true},
+		{name: "bool field as string", opts: []RouteOpt{WithRouteField("audit", "true")}, want: true},
+		{name: "number field", opts: []RouteOpt{WithRouteField("id", 7)}, want: true},
+		{name: "mismatch field", opts: []RouteOpt{WithRouteField("audit", false)}, want: false},
+		{name: "missing field", opts: []RouteOpt{WithRouteField("tenant", "x")}, want: false},
+		{
+			name: "all filters must pass",
+			opts: []RouteOpt{
+				WithRouteField("audit", true),
+				WithRouteFilter(func(e Entry) bool { return strings.HasPrefix(e.Message, "logout") }),
+			},
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRoutedWriter(&bufferWriter{}, tc.opts...)
+			assert.Equal(t, tc.want, rt.match(e))
+		})
+	}
+}
+
+func TestLoggerWithRoutedWriter(t *testing.T) {
+	builders := map[string]func(...Writer) Logger{"zap": NewZapLogger, "slog": NewSlogLogger}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			all, allObs := NewObserverWriter(TraceLevel, CONSOLE)
+			warn, warnObs := NewObserverWriter(WarnLevel, FILE)
+			audit, auditObs := NewObserverWriter(InfoLevel, FILE)
+			wr := fn(
+				all,
+				NewRoutedWriter(warn, WithRouteMaxLevel(ErrorLevel)),
+				NewAsyncWriter(NewRoutedWriter(audit, WithRouteField("audit", true))),
+			)
+			wr.Init(time.Microsecond)
+
+			cs := &countingStringer{}
+			wr.Inf("info", Stringer("payload", cs))
+			wr.Wrn("warn", Error(errors.New("slow")))
+			wr.Err("error")
+			wr.With(Bool("audit", true)).Inf("login", String("user", "kpm"))
+			wr.Group("req", Bool("audit", true)).Inf("nested audit")
+			wr.Inf("not audit", Bool("audit", false))
+			wr.Inf("overridden audit", Bool("audit", false), Bool("audit", true))
+			wr.Flush(time.Second)
+
+			assert.Equal(t, 7, allObs.Len())
+			// only encoded for the writer that accept it
+			assert.Equal(t, int32(1), cs.n.Load())
+
+			got := warnObs.All()
+			require.Len(t, got, 2)
+			assert.True(t, got[0].EqualMsg("warn"))
+			assert.True(t, got[1].EqualMsg("error"))
+
+			got = auditObs.All()
+			require.Len(t, got, 2)
+			assert.True(t, got[0].EqualMsg("login"))
+			assert.Equal(t, "kpm", got[0].Get("user"))
+			assert.True(t, got[1].EqualMsg("overridden audit"))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig config to sample repeated log entries. Each entry is
+// identified by their Level and message. On every Tick the first First
+// entries are logged, after that only every Thereafter entries is logged and
+// the rest is dropped. Dropped entries are reported periodically on every
+// Tick as a summary log entry, so nothing silently disappear.
+type SamplingConfig struct {
+	// Tick the sampling interval. Default to 1 second.
+	Tick time.Duration
+	// First the number of entries with the same Level and message that are
+	// always logged on every Tick.
+	First int
+	// Thereafter only log 1 in every Thereafter entries after the First. Zero
+	// means drop all entries after the First.
+	Thereafter int
+}
+
+// samplingSummaryMsg the message used by the summary log entry of dropped
+// entries.
+const samplingSummaryMsg = "log sampling dropped entries"
+
+// writerSampling SamplingConfig for certain Writer(s).
+type writerSampling struct {
+	cnf SamplingConfig
+	wr  []Writer
+}
+
+// samplingKey identify log entries that are sampled together.
+type samplingKey struct {
+	lvl Level
+	msg string
+}
+
+// newSampler return new sampler using given cnf.
+func newSampler(cnf SamplingConfig) *sampler {
+	if cnf.Tick <= 0 {
+		cnf.Tick = time.Second
+	}
+	return &sampler{
+		cnf:    cnf,
+		counts: make(map[samplingKey]int),
+		reset:  time.Now().Add(cnf.Tick),
+	}
+}
+
+// sampler decide whether a log entry should be logged or dropped based on the
+// SamplingConfig and keep track of the dropped entries. This is shared by
+// both zap and slog backend, so they behave exactly the same.
+type sampler struct {
+	cnf     SamplingConfig
+	mu      sync.Mutex
+	counts  map[samplingKey]int
+	reset   time.Time
+	dropped atomic.Uint64
+}
+
+// sample return true if the log entry with given lvl and msg should be
+// logged.
+func (s *sampler) sample(lvl Level, msg string) bool {
+	s.mu.Lock()
+	if now := time.Now(); now.After(s.reset) {
+		clear(s.counts)
+		s.reset = now.Add(s.cnf.Tick)
+	}
+	k := samplingKey{lvl: lvl, msg: msg}
+	s.counts[k]++
+	n := s.counts[k]
+	s.mu.Unlock()
+
+	if n <= s.cnf.First {
+		return true
+	}
+	if s.cnf.Thereafter > 0 && (n-s.cnf.First)%s.cnf.Thereafter == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// takeDropped return the number of dropped entries since the last call.
+func (s *sampler) takeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+// samplingReporter periodically report the dropped entries of a sampler using
+// given report func until stopped.
+type samplingReporter struct {
+	smp    *sampler
+	report func(dropped uint64)
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// newSamplingReporter return new running samplingReporter.
+func newSamplingReporter(smp *sampler, report func(dropped uint64)) *samplingReporter {
+	r := &samplingReporter{
+		smp:    smp,
+		report: report,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+func (r *samplingReporter) run() {
+	defer close(r.done)
+	t := time.NewTicker(r.smp.cnf.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			r.flush()
+		case <-r.stop:
+			r.flush()
+			return
+		}
+	}
+}
+
+// flush report the dropped entries if any.
+func (r *samplingReporter) flush() {
+	if n := r.smp.takeDropped(); n > 0 {
+		r.report(n)
+	}
+}
+
+// Stop stop the reporter and report any leftover dropped entries. Safe to be
+// called multiple times.
+func (r *samplingReporter) Stop() {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	t.Run("Should keep the first N then every M thereafter", func(t *testing.T) {
+		smp := newSampler(SamplingConfig{Tick: time.Minute, First: 2, Thereafter: 3})
+		var kept []int
+		for i := 1; i <= 10; i++ {
+			if smp.sample(InfoLevel, "msg") {
+				kept = append(kept, i)
+			}
+		}
+		assert.Equal(t, []int{1, 2, 5, 8}, kept)
+		assert.Equal(t, uint64(6), smp.takeDropped())
+		assert.Equal(t, uint64(0), smp.takeDropped())
+	})
+	t.Run("Should sample each level and message separately", func(t *testing.T) {
+		smp := newSampler(SamplingConfig{Tick: time.Minute, First: 1})
+		assert.True(t, smp.sample(InfoLevel, "msg"))
+		assert.False(t, smp.sample(InfoLevel, "msg"))
+		assert.True(t, smp.sample(ErrorLevel, "msg"))
+		assert.True(t, smp.sample(InfoLevel, "other msg"))
+	})
+	t.Run("Should reset the counter on every tick", func(t *testing.T) {
+		smp := newSampler(SamplingConfig{Tick: time.Millisecond, First: 1})
+		assert.True(t, smp.sample(InfoLevel, "msg"))
+		assert.False(t, smp.sample(InfoLevel, "msg"))
+		time.Sleep(2 * time.Millisecond)
+		assert.True(t, smp.sample(InfoLevel, "msg"))
+	})
+	t.Run("Should use default tick if not set", func(t *testing.T) {
+		smp := newSampler(SamplingConfig{})
+		assert.Equal(t, time.Second, smp.cnf.Tick)
+	})
+}
+
+func TestSamplingReporter(t *testing.T) {
+	smp := newSampler(SamplingConfig{Tick: time.Millisecond})
+	reported := make(chan uint64, 10)
+	r := newSamplingReporter(smp, func(dropped uint64) { reported <- dropped })
+
+	smp.sample(InfoLevel, "msg")
+	smp.sample(InfoLevel, "msg")
+	assert.Equal(t, uint64(2), <-reported)
+
+	r.Stop()
+	r.Stop() // safe to be called multiple times
+}
+
+func TestLoggerWithSampling(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			sampled, sampledObs := NewObserverWriter(DebugLevel, NEWRELIC)
+			full, fullObs := NewObserverWriter(DebugLevel, FILE)
+			cnf := SamplingConfig{Tick: time.Minute, First: 2, Thereafter: 3}
+			wr := fn([]Writer{sampled, full}, WithSampling(cnf, sampled))
+			wr.Init(time.Microsecond)
+
+			for i := 0; i < 10; i++ {
+				wr.Err("hot loop", Num("i", i))
+			}
+			assert.Equal(t, 10, fullObs.Len())
+			require.Equal(t, 4, sampledObs.Len())
+
+			// dropped entries is reported on flush
+			wr.Flush(time.Microsecond)
+			require.Equal(t, 5, sampledObs.Len())
+			summary := sampledObs.All()[4]
+			assert.True(t, summary.EqualLevel(WarnLevel))
+			assert.True(t, summary.EqualMsg(samplingSummaryMsg))
+			assert.Equal(t, float64(6), summary.Get("dropped"))
+			assert.Equal(t, 10, fullObs.Len())
+		})
+	}
+}
+
+func TestLoggerWithSamplingRespectWriterLevel(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			cnf := SamplingConfig{Tick: time.Minute, First: 2, Thereafter: 3}
+
+			t.Run("Disabled entries are not counted as dropped", func(t *testing.T) {
+				writer, obs := NewObserverWriter(WarnLevel, FILE)
+				wr := fn([]Writer{writer}, WithSampling(cnf, writer))
+				wr.Init(time.Microsecond)
+
+				for i := 0; i < 10; i++ {
+					wr.Inf("hot loop")
+					wr.Err("hot loop")
+				}
+				require.Equal(t, 4, obs.Len())
+
+				wr.Flush(time.Microsecond)
+				require.Equal(t, 5, obs.Len())
+				assert.Equal(t, float64(6), obs.All()[4].Get("dropped"))
+			})
+
+			t.Run("Summary is not written below the Writer level", func(t *testing.T) {
+				writer, obs := NewObserverWriter(ErrorLevel, FILE)
+				wr := fn([]Writer{writer}, WithSampling(cnf, writer))
+				wr.Init(time.Microsecond)
+
+				for i := 0; i < 10; i++ {
+					wr.Err("hot loop")
+				}
+				require.Equal(t, 4, obs.Len())
+
+				wr.Flush(time.Microsecond)
+				assert.Equal(t, 4, obs.Len())
+			})
+		})
+	}
+}
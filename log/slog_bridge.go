@@ -0,0 +1,160 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// NewSlogHandler return slog.Handler that write the log records through given
+// l, so the third-party library that accept *slog.Logger use the same
+// Writer(s), levels and redaction. The record above ErrorLevel is logged as
+// ErrorLevel, so the library can never panic or exit the process.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogBridge{l: l}
+}
+
+// ToSlog return *slog.Logger that write the log records through given l.
+func ToSlog(l Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// NewSlogLoggerFromHandler return Logger implementer that use given
+// slog.Handler as the backend after applying given opts, so the level and
+// encoding is decided by given h instead of the Writer(s). The options that
+// accept Writer(s) are only applied if none is given.
+func NewSlogLoggerFromHandler(h slog.Handler, opts ...Option) Logger {
+	return &slogLogger{h: h, opt: newOptions(opts...)}
+}
+
+// slogBridge slog.Handler implementer that write the log records through the
+// Logger.
+type slogBridge struct {
+	l Logger
+	// groups the open groups and attrs the attributes that is added within
+	// each of them, which are added as Namespace on every record.
+	groups []string
+	attrs  [][]Log
+}
+
+func (s *slogBridge) Enabled(_ context.Context, lvl slog.Level) bool {
+	if e, ok := s.l.(interface{ enabled(lvl Level) bool }); ok {
+		return e.enabled(bridgeLevel(lvl))
+	}
+	return true
+}
+func (s *slogBridge) Handle(ctx context.Context, r slog.Record) error {
+	pr := make([]Log, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		pr = appendSlogAttr(pr, a)
+		return true
+	})
+	for i := len(s.groups) - 1; i >= 0; i-- {
+		pr = append(append([]Log{}, s.attrs[i]...), pr...)
+		if len(pr) > 0 {
+			pr = []Log{Namespace(s.groups[i], pr...)}
+		}
+	}
+
+	l := s.l
+	if ctx != nil {
+		l = l.Ctx(ctx)
+	}
+	// keep the time and caller of the record
+	if rl, ok := l.(interface {
+		logRecord(lvl Level, t time.Time, pc uintptr, msg string, pr []Log)
+	}); ok {
+		rl.logRecord(bridgeLevel(r.Level), r.Time, r.PC, r.Message, pr)
+		return nil
+	}
+	switch bridgeLevel(r.Level) {
+	case TraceLevel:
+		l.Trc(r.Message, pr...)
+	case DebugLevel:
+		l.Dbg(r.Message, pr...)
+	case InfoLevel:
+		l.Inf(r.Message, pr...)
+	case WarnLevel:
+		l.Wrn(r.Message, pr...)
+	default:
+		l.Err(r.Message, pr...)
+	}
+	return nil
+}
+func (s *slogBridge) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var pr []Log
+	for _, a := range attrs {
+		pr = appendSlogAttr(pr, a)
+	}
+	if len(pr) == 0 {
+		return s
+	}
+
+	clone := *s
+	if len(s.groups) == 0 {
+		clone.l = s.l.With(pr...)
+		return &clone
+	}
+	// copy on write, so it does not affect the parent handler
+	clone.attrs = append([][]Log{}, s.attrs...)
+	last := len(clone.attrs) - 1
+	clone.attrs[last] = append(append([]Log{}, clone.attrs[last]...), pr...)
+	return &clone
+}
+func (s *slogBridge) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+	clone := *s
+	clone.groups = append(append([]string{}, s.groups...), name)
+	clone.attrs = append(append([][]Log{}, s.attrs...), nil)
+	return &clone
+}
+
+// bridgeLevel transform given slog level to local log Level up to ErrorLevel.
+func bridgeLevel(lvl slog.Level) Level {
+	return min(fromSlogLevel(lvl), ErrorLevel)
+}
+
+// appendSlogAttr transform given slog attribute to local Log and append it to
+// given pr. The empty attribute and empty group are ignored, while the group
+// without key is inlined the same way as slog.
+func appendSlogAttr(pr []Log, a slog.Attr) []Log {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return pr
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return append(pr, String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(pr, Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(pr, Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(pr, Float(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(pr, Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(pr, Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(pr, Time(a.Key, a.Value.Time()))
+	case slog.KindGroup:
+		var group []Log
+		for _, ga := range a.Value.Group() {
+			group = appendSlogAttr(group, ga)
+		}
+		if len(group) == 0 {
+			return pr
+		}
+		if a.Key == "" {
+			return append(pr, group...)
+		}
+		return append(pr, Namespace(a.Key, group...))
+	}
+	if err, ok := a.Value.Any().(error); ok && a.Key == "error" {
+		return append(pr, Error(err))
+	}
+	return append(pr, Any(a.Key, a.Value.Any()))
+}
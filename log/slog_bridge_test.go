@@ -0,0 +1,111 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestToSlog(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			w, obs := NewObserverWriter(InfoLevel, FILE)
+			wr := fn([]Writer{w}, WithRedaction(RedactPolicy{Keys: []string{"password"}}))
+			wr.Init(time.Microsecond)
+			sl := ToSlog(wr)
+
+			assert.False(t, sl.Enabled(context.Background(), slog.LevelDebug))
+			assert.True(t, sl.Enabled(context.Background(), slog.LevelInfo))
+
+			sl.Debug("filtered")
+			sl.With("lib", "pgx").WithGroup("req").With("id", 7).Info("query", "password", "secret", slog.Group("db", "rows", 2))
+			sl.Log(context.Background(), slog.LevelError+8, "not fatal", "error", errors.New("boom"))
+			sl.WithGroup("empty").Warn("no attrs")
+
+			ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{1},
+				SpanID:     trace.SpanID{2},
+				TraceFlags: trace.FlagsSampled,
+			}))
+			sl.InfoContext(ctx, "with span")
+
+			got := obs.All()
+			require.Len(t, got, 4)
+			assert.True(t, got[0].EqualMsg("query"))
+			assert.True(t, got[0].EqualLevel(InfoLevel))
+			assert.Equal(t, "pgx", got[0].Get("lib"))
+			assert.Equal(t, map[string]any{"id": float64(7), "password": "[REDACTED]", "db": map[string]any{"rows": float64(2)}}, got[0].Get("req"))
+
+			assert.True(t, got[1].EqualLevel(ErrorLevel))
+			assert.Equal(t, "boom", got[1].Get("error"))
+
+			assert.True(t, got[2].EqualLevel(WarnLevel))
+			assert.Nil(t, got[2].Get("empty"))
+
+			assert.Equal(t, trace.TraceID{1}.String(), got[3].Get("trace_id"))
+		})
+	}
+}
+
+func TestNewSlogLoggerFromHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	wr := NewSlogLoggerFromHandler(h, WithRedaction(RedactPolicy{Keys: []string{"password"}}))
+	wr.Init(time.Microsecond)
+
+	wr.Dbg("filtered")
+	wr.With(String("app", "api")).Inf("hello", String("password", "secret"))
+	wr.Flush(time.Microsecond)
+	assert.Equal(t, `{"level":"INFO","msg":"hello","app":"api","password":"[REDACTED]"}`+"\n", buf.String())
+}
+
+func TestSlogBridgeKeepRecord(t *testing.T) {
+	builders := map[string]func([]Writer, ...Option) Logger{
+		"zap":  NewZapLoggerWithOptions,
+		"slog": NewSlogLoggerWithOptions,
+	}
+	at := time.Date(2023, 9, 22, 13, 38, 39, 0, time.FixedZone("WIB", 7*3600))
+	for name, fn := range builders {
+		t.Run(name, func(t *testing.T) {
+			w, obs := NewObserverWriter(InfoLevel, FILE)
+			wr := fn([]Writer{w}, WithCaller())
+			wr.Init(time.Microsecond)
+
+			var pcs [1]uintptr
+			runtime.Callers(1, pcs[:])
+			r := slog.NewRecord(at, slog.LevelWarn, "from record", pcs[0])
+			r.AddAttrs(slog.Int("id", 7))
+			require.NoError(t, NewSlogHandler(wr).Handle(context.Background(), r))
+			r = slog.NewRecord(at, slog.LevelDebug, "filtered", pcs[0])
+			require.NoError(t, NewSlogHandler(wr).Handle(context.Background(), r))
+
+			got := obs.All()
+			require.Len(t, got, 1)
+			assert.True(t, got[0].EqualLevel(WarnLevel))
+			assert.Equal(t, at.Format(time.RFC3339), got[0].Get("time"))
+			assert.Contains(t, got[0].Get("caller"), "log/slog_bridge_test.go:")
+			assert.Equal(t, "github.com/mdanialr/api-pkg-go/log.TestSlogBridgeKeepRecord.func1", got[0].Get("function"))
+			assert.Equal(t, float64(7), got[0].Get("id"))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// newSlogEncoderHandler return slog.Handler that natively encode the log
+// record using given enc before writing it to given w.
+func newSlogEncoderHandler(w io.Writer, enc Encoder, lvl slog.Leveler) slog.Handler {
+	enc = enc.withDefault()
+	return &slogEncoderHandler{
+		enc: enc,
+		lvl: lvl,
+		mu:  &sync.Mutex{},
+		out: w,
+		pre: fieldEncoder{logfmt: enc.Format == LogfmtFormat},
+	}
+}
+
+// slogEncoderHandler slog.Handler implementer that encode the log record
+// using the Encoder. The attributes given to WithAttrs are encoded once, so
+// only the attributes of each record are encoded on every write.
+type slogEncoderHandler struct {
+	enc Encoder
+	lvl slog.Leveler
+	mu  *sync.Mutex
+	out io.Writer
+	// pre the encoded attributes given to WithAttrs
+	pre fieldEncoder
+	// groups the groups that are not opened yet in pre
+	groups []string
+	// opened the number of groups opened in pre
+	opened int
+}
+
+func (s *slogEncoderHandler) clone() *slogEncoderHandler {
+	c := *s
+	c.pre.buf = slices.Clip(s.pre.buf)
+	c.groups = slices.Clip(s.groups)
+	return &c
+}
+
+func (s *slogEncoderHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= s.lvl.Level()
+}
+
+func (s *slogEncoderHandler) Handle(_ context.Context, r slog.Record) error {
+	ent := encodedEntry{time: r.Time, level: fromSlogLevel(r.Level), msg: r.Message}
+	f := s.pre
+	f.buf = append(make([]byte, 0, len(s.pre.buf)+256), s.pre.buf...)
+	opened := s.opened
+	// just like slog.JSONHandler, the pending groups are only opened when the
+	// record has attributes
+	if r.NumAttrs() > 0 {
+		for _, g := range s.groups {
+			f.openObject(g)
+			opened++
+		}
+		top := opened == 0
+		r.Attrs(func(a slog.Attr) bool {
+			// the caller and stack trace added by slogLogger
+			if m, ok := a.Value.Any().(slogMeta); ok && top {
+				switch a.Key {
+				case "caller":
+					ent.caller = string(m)
+					return true
+				case "function":
+					ent.function = string(m)
+					return true
+				case "stacktrace":
+					ent.stack = string(m)
+					return true
+				}
+			}
+			addSlogAttr(&f, a)
+			return true
+		})
+	}
+	if !f.logfmt {
+		for ; opened > 0; opened-- {
+			f.closeObject("")
+		}
+	}
+
+	line := s.enc.appendEntry(make([]byte, 0, len(f.buf)+256), &ent, f.buf)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.out.Write(line)
+	return err
+}
+
+func (s *slogEncoderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return s
+	}
+	c := s.clone()
+	for _, g := range c.groups {
+		c.pre.openObject(g)
+		c.opened++
+	}
+	c.groups = nil
+	for _, a := range attrs {
+		addSlogAttr(&c.pre, a)
+	}
+	return c
+}
+
+func (s *slogEncoderHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+	c := s.clone()
+	c.groups = append(c.groups, name)
+	return c
+}
+
+// addSlogAttr encode given a the same way as slog.JSONHandler, except the time
+// and duration that are encoded the same way as zap backend.
+func addSlogAttr(f *fieldEncoder, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	switch v := a.Value; v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" {
+			// inline the group without key
+			for _, ga := range attrs {
+				addSlogAttr(f, ga)
+			}
+			return
+		}
+		prev := f.openObject(a.Key)
+		for _, ga := range attrs {
+			addSlogAttr(f, ga)
+		}
+		f.closeObject(prev)
+	case slog.KindString:
+		f.addString(a.Key, v.String())
+	case slog.KindInt64:
+		f.addInt(a.Key, v.Int64())
+	case slog.KindUint64:
+		f.addUint(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		f.addFloat(a.Key, v.Float64(), 64)
+	case slog.KindBool:
+		f.addBool(a.Key, v.Bool())
+	case slog.KindDuration:
+		f.addString(a.Key, v.Duration().String())
+	case slog.KindTime:
+		f.addString(a.Key, v.Time().Format(time.RFC3339Nano))
+	default:
+		val := v.Any()
+		if err, ok := val.(error); ok {
+			if _, ok = val.(json.Marshaler); !ok {
+				f.addString(a.Key, err.Error())
+				return
+			}
+		}
+		b, err := marshalJSON(val)
+		if err != nil {
+			f.addString(a.Key, "!ERROR:"+err.Error())
+			return
+		}
+		f.addJSON(a.Key, b)
+	}
+}
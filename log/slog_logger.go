@@ -1,20 +1,34 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 // NewSlogLogger return Logger implementer that use stdlib slog as the backend.
 func NewSlogLogger(wr ...Writer) Logger {
-	// set to singleton instead
-	singletonLogger = &slogLogger{wr: wr}
-	return singletonLogger
+	return NewSlogLoggerWithOptions(wr)
+}
+
+// NewSlogLoggerWithOptions return Logger implementer that use stdlib slog as
+// the backend after applying given opts.
+func NewSlogLoggerWithOptions(wr []Writer, opts ...Option) Logger {
+	return &slogLogger{wr: wr, opt: newOptions(opts...)}
 }
 
 type slogLogger struct {
 	log *multiSlog
 	wr  []Writer
+	// h the external slog.Handler used instead of the Writer(s) if any
+	h   slog.Handler
+	opt *options
+	smp []*samplingReporter
+	err writeErrorCounter
+	dur time.Duration
 }
 
 func (s *slogLogger) clone() *slogLogger {
@@ -22,22 +36,58 @@ func (s *slogLogger) clone() *slogLogger {
 	return &c
 }
 func (s *slogLogger) Init(dur time.Duration) {
+	// keep it, so the Writer(s) can be flushed on PanicLevel and FatalLevel
+	s.dur = dur
+
 	var slogs multiSlog
+	s.err = nil
 	for _, w := range s.wr {
-		switch w.Output() {
-		case CONSOLE:
-			opt := &slog.HandlerOptions{Level: toSlogLevel(w.Level())}
-			slogs.loggers = append(slogs.loggers, slog.New(slog.NewJSONHandler(w.Writer(), opt)))
-
-		case FILE, NEWRELIC:
-			opt := &slog.HandlerOptions{Level: toSlogLevel(w.Level())}
-			slogs.loggers = append(slogs.loggers, slog.New(slog.NewJSONHandler(w.Writer(), opt)))
+		opt := &slog.HandlerOptions{Level: slogLeveler{w}, ReplaceAttr: slogReplaceAttr}
+		ew := newErrorWriter(s.opt, w, s.opt.onWriteErr)
+		s.err = append(s.err, ew)
+		var h slog.Handler = slog.NewJSONHandler(ew, opt)
+		if e := s.opt.encoderFor(w); e != nil {
+			h = newSlogEncoderHandler(ew, *e, opt.Level)
 		}
+		slogs.loggers = append(slogs.loggers, slog.New(s.wrapHandler(w, h)))
 		w.Wait(dur)
 	}
+	if s.h != nil {
+		slogs.loggers = append(slogs.loggers, slog.New(s.wrapHandler(nil, s.h)))
+	}
 	s.log = &slogs
 }
+
+// wrapHandler wrap given handler based on the options that applied to given
+// w.
+func (s *slogLogger) wrapHandler(w Writer, h slog.Handler) slog.Handler {
+	if rt := routerOf(w); rt != nil {
+		h = &slogRouteHandler{Handler: h, rt: rt}
+	}
+	if rd := s.opt.redactorFor(w); rd != nil {
+		h = &slogRedactHandler{Handler: h, rd: rd}
+	}
+	if cnf := s.opt.samplingFor(w); cnf != nil {
+		smp, inner := newSampler(*cnf), h
+		s.smp = append(s.smp, newSamplingReporter(smp, func(dropped uint64) {
+			// only report to Writer that currently accept WARN level
+			if !inner.Enabled(context.Background(), slog.LevelWarn) {
+				return
+			}
+			r := slog.NewRecord(time.Now(), slog.LevelWarn, samplingSummaryMsg, 0)
+			r.AddAttrs(slog.Uint64("dropped", dropped), slog.Duration("tick", smp.cnf.Tick))
+			inner.Handle(context.Background(), r)
+		}))
+		h = &slogSamplingHandler{Handler: h, smp: smp}
+	}
+	return h
+}
+func (s *slogLogger) enabled(lvl Level) bool      { return s.log.Enabled(toSlogLevel(lvl)) }
+func (s *slogLogger) writeErrors(w Writer) uint64 { return s.err.writeErrors(w) }
 func (s *slogLogger) Flush(dur time.Duration) {
+	for _, r := range s.smp {
+		r.Stop()
+	}
 	for _, w := range s.wr {
 		w.Flush(dur)
 	}
@@ -46,66 +96,108 @@ func (s *slogLogger) With(pr ...Log) Logger {
 	if len(pr) == 0 {
 		return s
 	}
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	// clone it, so on every With method call does not affect the parent logger
 	clone := s.clone()
 	clone.log = clone.log.With(toSlogAttr(pr)...)
-
-	// then reassign to singleton
-	singletonLogger = clone
-
 	return clone
 }
 func (s *slogLogger) Group(key string, pr ...Log) Logger {
 	if len(pr) == 0 || key == "" {
 		return s
 	}
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	// clone it, so on every With method call does not affect the parent logger
 	clone := s.clone()
 	clone.log = clone.log.Group(key, toSlogAttr(pr)...)
-
-	// then reassign to singleton
-	singletonLogger = clone
-
 	return clone
 }
+func (s *slogLogger) Ctx(ctx context.Context) Logger {
+	return s.With(ctxFields(ctx, s.opt.extractors)...)
+}
+func (s *slogLogger) Trc(msg string, pr ...Log) {
+	s.log.Log(slogTraceLevel, msg, s.attrs(slogTraceLevel, pr)...)
+}
 func (s *slogLogger) Dbg(msg string, pr ...Log) {
-	if len(pr) > 0 {
-		s.log.Debug(msg, toSlogAttr(pr)...)
-		return
-	}
-	s.log.Debug(msg)
+	s.log.Debug(msg, s.attrs(slog.LevelDebug, pr)...)
 }
 func (s *slogLogger) Inf(msg string, pr ...Log) {
-	if len(pr) > 0 {
-		s.log.Info(msg, toSlogAttr(pr)...)
-		return
-	}
-	s.log.Info(msg)
+	s.log.Info(msg, s.attrs(slog.LevelInfo, pr)...)
 }
 func (s *slogLogger) Wrn(msg string, pr ...Log) {
-	if len(pr) > 0 {
-		s.log.Warn(msg, toSlogAttr(pr)...)
-		return
-	}
-	s.log.Warn(msg, toSlogAttr(pr)...)
+	s.log.Warn(msg, s.attrs(slog.LevelWarn, pr)...)
 }
 func (s *slogLogger) Err(msg string, pr ...Log) {
-	if len(pr) > 0 {
-		s.log.Error(msg, toSlogAttr(pr)...)
+	s.log.Error(msg, s.attrs(slog.LevelError, pr)...)
+}
+func (s *slogLogger) Pnc(msg string, pr ...Log) {
+	s.log.Log(slogPanicLevel, msg, s.attrs(slogPanicLevel, pr)...)
+	terminate(PanicLevel, msg, func() { s.Flush(s.dur) })
+}
+func (s *slogLogger) Ftl(msg string, pr ...Log) {
+	s.log.Log(slogFatalLevel, msg, s.attrs(slogFatalLevel, pr)...)
+	terminate(FatalLevel, msg, func() { s.Flush(s.dur) })
+}
+
+// logRecord log given msg and pr at given lvl with given time and the caller
+// of given pc instead of the current ones. Zero t and pc are ignored.
+func (s *slogLogger) logRecord(lvl Level, t time.Time, pc uintptr, msg string, pr []Log) {
+	sl := toSlogLevel(lvl)
+	if !s.log.Enabled(sl) {
 		return
 	}
-	s.log.Error(msg)
+	attrs := toSlogAttr(pr)
+	if frame, ok := frameOf(pc); ok && s.opt.caller {
+		caller := zapcore.NewEntryCaller(pc, frame.File, frame.Line, true).TrimmedPath()
+		attrs = append(attrs, slogMetaAttr("caller", caller), slogMetaAttr("function", frame.Function))
+	}
+	if s.opt.stack && sl >= toSlogLevel(s.opt.stackLvl) {
+		attrs = append(attrs, slogMetaAttr("stacktrace", stacktrace(1)))
+	}
+	if t.IsZero() {
+		t = time.Now()
+	}
+	r := slog.NewRecord(t, sl, msg, pc)
+	r.Add(attrs...)
+	s.log.Handle(r)
+}
+
+// attrs transform given pr to slog attributes then add the caller and stack
+// trace if enabled. Must be called directly by the slogLogger method, so the
+// reported caller is correct.
+func (s *slogLogger) attrs(lvl slog.Level, pr []Log) []any {
+	attrs := toSlogAttr(pr)
+	if !s.opt.caller && !s.opt.stack || !s.log.Enabled(lvl) {
+		return attrs
+	}
+
+	// skip attrs itself and the slogLogger method
+	skip := 2 + s.opt.callerSkip
+	if s.opt.caller {
+		if caller, fn, ok := callerOf(skip); ok {
+			attrs = append(attrs, slogMetaAttr("caller", caller), slogMetaAttr("function", fn))
+		}
+	}
+	if s.opt.stack && lvl >= toSlogLevel(s.opt.stackLvl) {
+		attrs = append(attrs, slogMetaAttr("stacktrace", stacktrace(skip)))
+	}
+	return attrs
 }
 
+// slog does not have trace, panic and fatal level, so use the same gap as
+// the builtin slog levels.
+const (
+	slogTraceLevel = slog.LevelDebug - 4
+	slogPanicLevel = slog.LevelError + 4
+	slogFatalLevel = slog.LevelError + 8
+	// slogInvalidLevel is above every level, so nothing is enabled just like
+	// zapcore.InvalidLevel in zap.
+	slogInvalidLevel = slogFatalLevel + 4
+)
+
 // toSlogLevel transform local log Level to slog level.
 func toSlogLevel(lvl Level) slog.Level {
 	switch lvl {
+	case TraceLevel:
+		return slogTraceLevel
 	case DebugLevel:
 		return slog.LevelDebug
 	case InfoLevel:
@@ -114,10 +206,41 @@ func toSlogLevel(lvl Level) slog.Level {
 		return slog.LevelWarn
 	case ErrorLevel:
 		return slog.LevelError
+	case PanicLevel:
+		return slogPanicLevel
+	case FatalLevel:
+		return slogFatalLevel
 	}
-	return -1
+	return slogInvalidLevel
+}
+
+// fromSlogLevel transform slog level to local log Level.
+func fromSlogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TraceLevel
+	case lvl < slog.LevelInfo:
+		return DebugLevel
+	case lvl < slog.LevelWarn:
+		return InfoLevel
+	case lvl < slog.LevelError:
+		return WarnLevel
+	case lvl < slogPanicLevel:
+		return ErrorLevel
+	case lvl < slogFatalLevel:
+		return PanicLevel
+	}
+	return FatalLevel
+}
+
+// slogLeveler slog.Leveler implementer that always read the current Level of
+// the Writer, so any changes to the Writer Level at runtime is respected.
+type slogLeveler struct {
+	w Writer
 }
 
+func (s slogLeveler) Level() slog.Level { return toSlogLevel(s.w.Level()) }
+
 // toSlogAttr transform local Log to specific slog field.
 func toSlogAttr(pr []Log) []any {
 	var attrs []any
@@ -135,11 +258,192 @@ func toSlogAttr(pr []Log) []any {
 			attrs = append(attrs, slog.Any(p.key, p.any))
 		case ErrorType:
 			attrs = append(attrs, slog.Any(p.key, p.err))
+		case DurationType:
+			attrs = append(attrs, slog.Duration(p.key, time.Duration(p.i64)))
+		case TimeType:
+			attrs = append(attrs, slog.Time(p.key, p.any.(time.Time)))
+		case Int64Type:
+			attrs = append(attrs, slog.Int64(p.key, p.i64))
+		case Uint64Type:
+			attrs = append(attrs, slog.Uint64(p.key, p.u64))
+		case StringsType, IntsType, BinaryType:
+			attrs = append(attrs, slog.Any(p.key, p.any))
+		case StringerType:
+			attrs = append(attrs, slog.Any(p.key, slogStringer{p.any.(fmt.Stringer)}))
+		case BytesType:
+			attrs = append(attrs, slog.String(p.key, string(p.any.([]byte))))
+		case NamespaceType:
+			attrs = append(attrs, slog.Group(p.key, toSlogAttr(p.any.([]Log))...))
 		}
 	}
 	return attrs
 }
 
+// slogStringer slog.LogValuer implementer that lazily call the String method
+// only when the log record is written.
+type slogStringer struct {
+	v fmt.Stringer
+}
+
+func (s slogStringer) LogValue() slog.Value { return slog.StringValue(s.v.String()) }
+
+// slogMeta slog.LogValuer implementer that mark the caller and stack trace
+// added by slogLogger, so the Encoder can tell them apart from the user
+// attributes of the same key. It's resolved as plain string by any other
+// handler.
+type slogMeta string
+
+func (s slogMeta) LogValue() slog.Value { return slog.StringValue(string(s)) }
+
+// slogMetaAttr return the attribute of given key and val marked as slogMeta.
+func slogMetaAttr(key, val string) slog.Attr { return slog.Any(key, slogMeta(val)) }
+
+// slogReplaceAttr encode level, time and duration the same way as zap backend.
+func slogReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if lvl, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(a.Key, fromSlogLevel(lvl).String())
+		}
+	}
+	switch a.Value.Kind() {
+	case slog.KindTime:
+		return slog.String(a.Key, a.Value.Time().Format(time.RFC3339))
+	case slog.KindDuration:
+		return slog.String(a.Key, a.Value.Duration().String())
+	}
+	return a
+}
+
+// slogSamplingHandler slog.Handler implementer that sample the log records
+// before passing it to the underlying handler.
+type slogSamplingHandler struct {
+	slog.Handler
+	smp *sampler
+}
+
+func (s *slogSamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	// make sure disabled records are never counted by the sampler
+	if !s.Enabled(ctx, r.Level) || !s.smp.sample(fromSlogLevel(r.Level), r.Message) {
+		return nil
+	}
+	return s.Handler.Handle(ctx, r)
+}
+func (s *slogSamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &slogSamplingHandler{Handler: s.Handler.WithAttrs(attrs), smp: s.smp}
+}
+func (s *slogSamplingHandler) WithGroup(name string) slog.Handler {
+	return &slogSamplingHandler{Handler: s.Handler.WithGroup(name), smp: s.smp}
+}
+
+// slogRouteHandler slog.Handler implementer that only pass the log records
+// that match the RoutedWriter to the underlying handler.
+type slogRouteHandler struct {
+	slog.Handler
+	rt *RoutedWriter
+	// attrs the accumulated top-level attributes, so the filter can match it
+	// as well
+	attrs   []slog.Attr
+	grouped bool
+}
+
+func (s *slogRouteHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return s.rt.enabled(fromSlogLevel(lvl)) && s.Handler.Enabled(ctx, lvl)
+}
+func (s *slogRouteHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := Entry{Level: fromSlogLevel(r.Level), Message: r.Message, field: func(key string) (v any, ok bool) {
+		// the record attributes belong to the group if any
+		if !s.grouped {
+			r.Attrs(func(a slog.Attr) bool {
+				if a.Key == key {
+					v, ok = fieldValue(a.Value.Resolve().Any()), true
+				}
+				return true
+			})
+		}
+		if ok {
+			return v, ok
+		}
+		for i := len(s.attrs) - 1; i >= 0; i-- {
+			if s.attrs[i].Key == key {
+				return fieldValue(s.attrs[i].Value.Resolve().Any()), true
+			}
+		}
+		return nil, false
+	}}
+	if !s.rt.match(e) {
+		return nil
+	}
+	return s.Handler.Handle(ctx, r)
+}
+func (s *slogRouteHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	acc := s.attrs
+	if !s.grouped {
+		acc = append(append([]slog.Attr{}, s.attrs...), attrs...)
+	}
+	return &slogRouteHandler{Handler: s.Handler.WithAttrs(attrs), rt: s.rt, attrs: acc, grouped: s.grouped}
+}
+func (s *slogRouteHandler) WithGroup(name string) slog.Handler {
+	return &slogRouteHandler{Handler: s.Handler.WithGroup(name), rt: s.rt, attrs: s.attrs, grouped: true}
+}
+
+// slogRedactHandler slog.Handler implementer that redact the sensitive
+// attributes before passing it to the underlying handler.
+type slogRedactHandler struct {
+	slog.Handler
+	rd     *redactor
+	groups []string
+}
+
+func (s *slogRedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(s.redact(s.groups, a))
+		return true
+	})
+	return s.Handler.Handle(ctx, nr)
+}
+func (s *slogRedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		res[i] = s.redact(s.groups, a)
+	}
+	return &slogRedactHandler{Handler: s.Handler.WithAttrs(res), rd: s.rd, groups: s.groups}
+}
+func (s *slogRedactHandler) WithGroup(name string) slog.Handler {
+	groups := append(append([]string{}, s.groups...), name)
+	return &slogRedactHandler{Handler: s.Handler.WithGroup(name), rd: s.rd, groups: groups}
+}
+
+// redact return copy of given a with any sensitive value redacted. Given
+// groups is the path of the group where a is located.
+func (s *slogRedactHandler) redact(groups []string, a slog.Attr) slog.Attr {
+	// keep the caller and stack trace marked for the Encoder
+	if _, ok := a.Value.Any().(slogMeta); ok {
+		return a
+	}
+	a.Value = a.Value.Resolve()
+	keys := append(append([]string{}, groups...), a.Key)
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		sub := a.Value.Group()
+		res := make([]any, len(sub))
+		for i, sa := range sub {
+			res[i] = s.redact(keys, sa)
+		}
+		return slog.Group(a.Key, res...)
+	case slog.KindAny:
+		if nv, changed := s.rd.redact(keys, a.Value.Any()); changed {
+			return slog.Any(a.Key, nv)
+		}
+	default:
+		if m, ok := s.rd.match(keys); ok {
+			return slog.String(a.Key, mask(m, a.Value.Any()))
+		}
+	}
+	return a
+}
+
 // multiSlog add support to write logs to multiple slog.Logger.
 type multiSlog struct {
 	loggers []*slog.Logger
@@ -159,6 +463,26 @@ func (m *multiSlog) Group(key string, args ...any) *multiSlog {
 	}
 	return &multiSlog{loggers: clone}
 }
+func (m *multiSlog) Handle(r slog.Record) {
+	for _, log := range m.loggers {
+		if h := log.Handler(); h.Enabled(context.Background(), r.Level) {
+			h.Handle(context.Background(), r)
+		}
+	}
+}
+func (m *multiSlog) Enabled(lvl slog.Level) bool {
+	for _, log := range m.loggers {
+		if log.Enabled(context.Background(), lvl) {
+			return true
+		}
+	}
+	return false
+}
+func (m *multiSlog) Log(lvl slog.Level, msg string, args ...any) {
+	for _, log := range m.loggers {
+		log.Log(context.Background(), lvl, msg, args...)
+	}
+}
 func (m *multiSlog) Debug(msg string, args ...any) {
 	for _, log := range m.loggers {
 		log.Debug(msg, args...)
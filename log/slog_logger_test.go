@@ -18,6 +18,11 @@ func TestToSlogLevel(t *testing.T) {
 		sample Level
 		expect slog.Level
 	}{
+		{
+			name:   "Trace level",
+			sample: TraceLevel,
+			expect: slogTraceLevel,
+		},
 		{
 			name:   "Debug level",
 			sample: DebugLevel,
@@ -38,10 +43,20 @@ func TestToSlogLevel(t *testing.T) {
 			sample: ErrorLevel,
 			expect: slog.LevelError,
 		},
+		{
+			name:   "Panic level",
+			sample: PanicLevel,
+			expect: slogPanicLevel,
+		},
+		{
+			name:   "Fatal level",
+			sample: FatalLevel,
+			expect: slogFatalLevel,
+		},
 		{
 			name:   "Unrecognized level",
-			sample: -1,
-			expect: -1,
+			sample: InvalidLevel,
+			expect: slogInvalidLevel,
 		},
 	}
 
@@ -88,6 +103,56 @@ func TestToSlogAttr(t *testing.T) {
 			sample: Error(errors.New("oops")),
 			expect: []any{slog.Any("error", errors.New("oops"))},
 		},
+		{
+			name:   "Duration attribute",
+			sample: Duration("latency", time.Second),
+			expect: []any{slog.Duration("latency", time.Second)},
+		},
+		{
+			name:   "Time attribute",
+			sample: Time("at", time.Unix(0, 0)),
+			expect: []any{slog.Time("at", time.Unix(0, 0))},
+		},
+		{
+			name:   "Int64 attribute",
+			sample: Int64("id", 1<<40),
+			expect: []any{slog.Int64("id", 1<<40)},
+		},
+		{
+			name:   "Uint64 attribute",
+			sample: Uint64("id", 1<<63),
+			expect: []any{slog.Uint64("id", 1<<63)},
+		},
+		{
+			name:   "Strings attribute",
+			sample: Strings("tags", []string{"a"}),
+			expect: []any{slog.Any("tags", []string{"a"})},
+		},
+		{
+			name:   "Ints attribute",
+			sample: Ints("ids", []int{1}),
+			expect: []any{slog.Any("ids", []int{1})},
+		},
+		{
+			name:   "Stringer attribute",
+			sample: Stringer("lvl", InfoLevel),
+			expect: []any{slog.Any("lvl", slogStringer{InfoLevel})},
+		},
+		{
+			name:   "Bytes attribute",
+			sample: Bytes("body", []byte(`{"a":1}`)),
+			expect: []any{slog.String("body", `{"a":1}`)},
+		},
+		{
+			name:   "Binary attribute",
+			sample: Binary("raw", []byte{1}),
+			expect: []any{slog.Any("raw", []byte{1})},
+		},
+		{
+			name:   "Namespace attribute",
+			sample: Namespace("user", String("id", "007")),
+			expect: []any{slog.Group("user", slog.String("id", "007"))},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+)
+
+// NewStdLog return stdlib *log.Logger that write every line through given l
+// at given lvl, such as for http.Server ErrorLog.
+func NewStdLog(l Logger, lvl Level) *stdlog.Logger {
+	return stdlog.New(&stdLogWriter{l: l, lvl: lvl}, "", 0)
+}
+
+// RedirectStdLog redirect the output of the stdlib log package such as
+// log.Printf to given l at given lvl. Return the function that restore the
+// previous output, prefix and flags. Note that log.Fatal and log.Panic still
+// exit or panic as usual, but they are logged at given lvl.
+func RedirectStdLog(l Logger, lvl Level) func() {
+	flags, prefix, out := stdlog.Flags(), stdlog.Prefix(), stdlog.Writer()
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(&stdLogWriter{l: l, lvl: lvl})
+	return func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetOutput(out)
+	}
+}
+
+// stdLogWriter io.Writer implementer that log every line written by the
+// stdlib log package through the Logger.
+type stdLogWriter struct {
+	l   Logger
+	lvl Level
+}
+
+func (s *stdLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte("\n")))
+	switch s.lvl {
+	case TraceLevel:
+		s.l.Trc(msg)
+	case DebugLevel:
+		s.l.Dbg(msg)
+	case InfoLevel:
+		s.l.Inf(msg)
+	case WarnLevel:
+		s.l.Wrn(msg)
+	default:
+		// never panic or exit from here, the stdlib log package does it
+		// itself if needed
+		s.l.Err(msg)
+	}
+	return len(p), nil
+}
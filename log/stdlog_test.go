@@ -0,0 +1,41 @@
+package log
+
+import (
+	stdlog "log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	w, obs := NewObserverWriter(DebugLevel, FILE)
+	wr := NewZapLogger(w)
+	wr.Init(time.Microsecond)
+
+	restore := RedirectStdLog(wr, WarnLevel)
+	stdlog.Printf("dial %s failed", "db")
+	stdlog.Print("no newline")
+	restore()
+	stdlog.Print("not redirected")
+
+	got := obs.All()
+	require.Len(t, got, 2)
+	assert.True(t, got[0].EqualMsg("dial db failed"))
+	assert.True(t, got[0].EqualLevel(WarnLevel))
+	assert.True(t, got[1].EqualMsg("no newline"))
+	assert.Equal(t, stdlog.LstdFlags, stdlog.Flags())
+}
+
+func TestNewStdLog(t *testing.T) {
+	w, obs := NewObserverWriter(DebugLevel, FILE)
+	wr := NewSlogLogger(w)
+	wr.Init(time.Microsecond)
+
+	NewStdLog(wr, PanicLevel).Println("http: TLS handshake error")
+	require.Equal(t, 1, obs.Len())
+	assert.True(t, obs.All()[0].EqualMsg("http: TLS handshake error"))
+	// never panic from the std log Writer
+	assert.True(t, obs.All()[0].EqualLevel(ErrorLevel))
+}
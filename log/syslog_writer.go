@@ -0,0 +1,252 @@
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFacility the syslog facility code.
+type SyslogFacility int8
+
+const (
+	FacilityKern   SyslogFacility = iota // FacilityKern kernel messages
+	FacilityUser                         // FacilityUser user-level messages
+	FacilityMail                         // FacilityMail mail system
+	FacilityDaemon                       // FacilityDaemon system daemons
+)
+
+const (
+	FacilityLocal0 SyslogFacility = iota + 16 // FacilityLocal0 local use 0
+	FacilityLocal1                            // FacilityLocal1 local use 1
+	FacilityLocal2                            // FacilityLocal2 local use 2
+	FacilityLocal3                            // FacilityLocal3 local use 3
+	FacilityLocal4                            // FacilityLocal4 local use 4
+	FacilityLocal5                            // FacilityLocal5 local use 5
+	FacilityLocal6                            // FacilityLocal6 local use 6
+	FacilityLocal7                            // FacilityLocal7 local use 7
+)
+
+// syslogDialTimeout the timeout used when connecting on write.
+const syslogDialTimeout = 5 * time.Second
+
+// errSyslogBackoff returned when writing while waiting for the next
+// reconnection attempt.
+var errSyslogBackoff = errors.New("syslog: waiting to reconnect")
+
+// NewSyslogWriter return Writer implementer that write logs to syslog server
+// by given Config.Syslog using RFC 5424 format and set given lvl as the log
+// Level. The connection is established on Wait or the first write and
+// reconnected with exponential backoff whenever it's dropped.
+func NewSyslogWriter(lvl Level, cnf *Config) Writer {
+	if cnf == nil {
+		cnf = &Config{}
+	}
+
+	s := &syslogOutput{lvl: NewAtomicLevel(lvl), cnf: cnf.Syslog, fac: FacilityUser}
+	// set default value
+	if s.cnf.Network == "" {
+		s.cnf.Network = "udp"
+	}
+	if s.cnf.AppName == "" {
+		s.cnf.AppName = filepath.Base(os.Args[0])
+	}
+	if s.cnf.Hostname == "" {
+		s.cnf.Hostname, _ = os.Hostname()
+	}
+	if s.cnf.Facility != nil {
+		s.fac = *s.cnf.Facility
+	}
+	if s.cnf.SDID == "" {
+		s.cnf.SDID = "log@32473"
+	}
+	if s.cnf.MaxBackoff == 0 {
+		s.cnf.MaxBackoff = 30 * time.Second
+	}
+	s.header = syslogHeader(&s.cnf)
+	return s
+}
+
+type syslogOutput struct {
+	lvl    *AtomicLevel
+	cnf    SyslogConfig
+	fac    SyslogFacility
+	header string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// Write implement io.Writer. Each given b is expected to be a single JSON
+// encoded log line.
+func (s *syslogOutput) Write(b []byte) (int, error) {
+	msg := s.format(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.connect(syslogDialTimeout); err != nil {
+		return 0, err
+	}
+	if _, err := s.conn.Write(msg); err != nil {
+		// the connection may be dropped by the server, reconnect and try
+		// once more
+		s.close()
+		if err = s.connect(syslogDialTimeout); err != nil {
+			return 0, err
+		}
+		if _, err = s.conn.Write(msg); err != nil {
+			s.close()
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// connect dial the syslog server if not connected yet. Must be called while
+// holding the lock.
+func (s *syslogOutput) connect(timeout time.Duration) error {
+	if s.conn != nil {
+		return nil
+	}
+	if time.Now().Before(s.retryAt) {
+		return errSyslogBackoff
+	}
+
+	conn, err := s.dial(timeout)
+	if err != nil {
+		s.backoff = min(max(2*s.backoff, 100*time.Millisecond), s.cnf.MaxBackoff)
+		s.retryAt = time.Now().Add(s.backoff)
+		return err
+	}
+	s.conn, s.backoff, s.retryAt = conn, 0, time.Time{}
+	return nil
+}
+
+// dial the syslog server based on the configured network.
+func (s *syslogOutput) dial(timeout time.Duration) (net.Conn, error) {
+	d := &net.Dialer{Timeout: timeout}
+	if s.cnf.Network == "tls" {
+		return tls.DialWithDialer(d, "tcp", s.cnf.Addr, s.cnf.TLS)
+	}
+	return d.Dial(s.cnf.Network, s.cnf.Addr)
+}
+
+// close the current connection. Must be called while holding the lock.
+func (s *syslogOutput) close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// format given JSON encoded log line as RFC 5424 syslog message, framed using
+// octet-counting for stream based network.
+func (s *syslogOutput) format(b []byte) []byte {
+	b = bytes.TrimSpace(b)
+	lvl := levelOf(b)
+
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(int(s.fac)*8 + syslogSeverity(lvl)))
+	buf.WriteString(">1 ")
+	buf.WriteString(entryTime(b, time.Now()).Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteString(s.header)
+	buf.WriteString(`[` + s.cnf.SDID + ` level="` + lvl.String() + `"`)
+	keys := make([]string, 0, len(s.cnf.Data))
+	for k := range s.cnf.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(" " + syslogName(k, 32) + `="` + syslogParamValue(s.cnf.Data[k]) + `"`)
+	}
+	buf.WriteString("] ")
+	buf.Write(b)
+
+	switch s.cnf.Network {
+	case "tcp", "tls", "unix":
+		return append([]byte(strconv.Itoa(buf.Len())+" "), buf.Bytes()...)
+	}
+	return buf.Bytes()
+}
+
+func (s *syslogOutput) Writer() io.Writer  { return s }
+func (s *syslogOutput) Output() Output     { return SYSLOG }
+func (s *syslogOutput) Level() Level       { return s.lvl.Level() }
+func (s *syslogOutput) SetLevel(lvl Level) { s.lvl.SetLevel(lvl) }
+
+// Wait try to connect to the syslog server within given dur.
+func (s *syslogOutput) Wait(dur time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connect(dur)
+}
+
+// Flush close the connection to the syslog server.
+func (s *syslogOutput) Flush(_ time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.close()
+}
+
+// syslogSeverity map given Level to syslog severity.
+func syslogSeverity(lvl Level) int {
+	switch lvl {
+	case TraceLevel, DebugLevel:
+		return 7 // debug
+	case WarnLevel:
+		return 4 // warning
+	case ErrorLevel:
+		return 3 // err
+	case PanicLevel:
+		return 2 // crit
+	case FatalLevel:
+		return 1 // alert
+	}
+	return 6 // informational
+}
+
+// syslogHeader return the static part of the RFC 5424 header that follows
+// the timestamp, which are HOSTNAME, APP-NAME, PROCID and MSGID.
+func syslogHeader(cnf *SyslogConfig) string {
+	return " " + syslogName(cnf.Hostname, 255) +
+		" " + syslogName(cnf.AppName, 48) +
+		" " + strconv.Itoa(os.Getpid()) +
+		" - "
+}
+
+// syslogName return given s that only contains printable US-ASCII and is
+// truncated to given n length as required by RFC 5424 header fields. Return
+// the NILVALUE '-' if s is empty.
+func syslogName(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > n {
+		s = s[:n]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogParamValue escape '"', '\' and ']' in given structured data
+// parameter value.
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,187 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyslogWriter(t *testing.T) {
+	t.Run("Default config", func(t *testing.T) {
+		wr := NewSyslogWriter(InfoLevel, nil)
+		require.NotNil(t, wr)
+		assert.Equal(t, SYSLOG, wr.Output())
+		assert.Equal(t, InfoLevel, wr.Level())
+		wr.(LevelSetter).SetLevel(DebugLevel)
+		assert.Equal(t, DebugLevel, wr.Level())
+
+		s := wr.(*syslogOutput)
+		assert.Equal(t, "udp", s.cnf.Network)
+		assert.Equal(t, FacilityUser, s.fac)
+		assert.Equal(t, 30*time.Second, s.cnf.MaxBackoff)
+	})
+	t.Run("Should use kern facility set in the struct", func(t *testing.T) {
+		kern := FacilityKern
+		s := NewSyslogWriter(InfoLevel, &Config{Syslog: SyslogConfig{Facility: &kern}}).(*syslogOutput)
+		assert.Equal(t, FacilityKern, s.fac)
+	})
+	t.Run("Should use explicitly set kern facility and the log time", func(t *testing.T) {
+		cnf := NewConfig(
+			WithSyslogFacility(FacilityKern),
+			WithSyslogAppName("app"),
+			WithSyslogHostname("host-1"),
+		)
+		s := NewSyslogWriter(InfoLevel, cnf).(*syslogOutput)
+		assert.Equal(t, FacilityKern, s.fac)
+
+		msg := string(s.format([]byte(`{"level":"WARN","time":"2023-09-22T13:38:39.123+07:00","msg":"hi"}`)))
+		// kern (0) * 8 + warning (4)
+		assert.True(t, strings.HasPrefix(msg, "<4>1 2023-09-22T13:38:39.123000+07:00 host-1 app "), msg)
+	})
+	t.Run("UDP should write one message per datagram", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer pc.Close()
+
+		cnf := NewConfig(
+			WithSyslogAddr("udp", pc.LocalAddr().String()),
+			WithSyslogAppName("my app"),
+			WithSyslogHostname("host-1"),
+			WithSyslogFacility(FacilityLocal0),
+			WithSyslogData("env", `pr"od`),
+		)
+		wr := NewZapLogger(NewSyslogWriter(DebugLevel, cnf))
+		wr.Init(time.Second)
+		wr.Err("oops!!", String("hello", "world"))
+		wr.Flush(time.Second)
+
+		buf := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+
+		msg := string(buf[:n])
+		// local0 (16) * 8 + err (3)
+		assert.True(t, strings.HasPrefix(msg, "<131>1 "), msg)
+		assert.Contains(t, msg, ` host-1 my_app `)
+		assert.Contains(t, msg, ` - [log@32473 level="ERROR" env="pr\"od"] {`)
+		assert.Contains(t, msg, `"msg":"oops!!","hello":"world"}`)
+	})
+	t.Run("TCP should use octet-counting and reconnect", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		msgs := make(chan string, 10)
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go readOctetCounted(conn, msgs)
+			}
+		}()
+
+		sw := NewSyslogWriter(DebugLevel, NewConfig(WithSyslogAddr("tcp", ln.Addr().String())))
+		sw.Wait(time.Second)
+		wr := NewSlogLogger(sw)
+		wr.Init(time.Second)
+
+		wr.Inf("first")
+		assert.Contains(t, <-msgs, `"msg":"first"`)
+
+		// drop the connection from the client side to simulate broken pipe
+		sw.(*syslogOutput).mu.Lock()
+		sw.(*syslogOutput).conn.Close()
+		sw.(*syslogOutput).mu.Unlock()
+
+		wr.Wrn("second")
+		msg := <-msgs
+		assert.True(t, strings.HasPrefix(msg, "<12>1 "), msg)
+		assert.Contains(t, msg, `"msg":"second"`)
+		wr.Flush(time.Second)
+	})
+	t.Run("Unix datagram socket", func(t *testing.T) {
+		addr := filepath.Join(t.TempDir(), "log.sock")
+		pc, err := net.ListenPacket("unixgram", addr)
+		require.NoError(t, err)
+		defer pc.Close()
+
+		wr := NewSyslogWriter(DebugLevel, NewConfig(WithSyslogAddr("unixgram", addr)))
+		_, err = wr.Writer().Write([]byte(`{"level":"DEBUG","msg":"hi"}` + "\n"))
+		require.NoError(t, err)
+
+		buf := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(buf[:n]), "<15>1 "))
+		assert.True(t, strings.HasSuffix(string(buf[:n]), `] {"level":"DEBUG","msg":"hi"}`))
+	})
+	t.Run("Should backoff when server is unreachable", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		ln.Close()
+
+		wr := NewSyslogWriter(DebugLevel, NewConfig(WithSyslogAddr("tcp", addr)))
+		_, err = wr.Writer().Write([]byte(`{"level":"INFO"}`))
+		require.Error(t, err)
+		_, err = wr.Writer().Write([]byte(`{"level":"INFO"}`))
+		assert.ErrorIs(t, err, errSyslogBackoff)
+		assert.Equal(t, 100*time.Millisecond, wr.(*syslogOutput).backoff)
+	})
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	testCases := []struct {
+		sample Level
+		expect int
+	}{
+		{sample: TraceLevel, expect: 7},
+		{sample: DebugLevel, expect: 7},
+		{sample: InfoLevel, expect: 6},
+		{sample: WarnLevel, expect: 4},
+		{sample: ErrorLevel, expect: 3},
+		{sample: PanicLevel, expect: 2},
+		{sample: FatalLevel, expect: 1},
+		{sample: InvalidLevel, expect: 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sample.String(), func(t *testing.T) {
+			assert.Equal(t, tc.expect, syslogSeverity(tc.sample))
+		})
+	}
+}
+
+// readOctetCounted read every octet-counted syslog message from given conn
+// and send it to given msgs.
+func readOctetCounted(conn net.Conn, msgs chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		ln, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(ln))
+		if err != nil {
+			return
+		}
+		buf := make([]byte, n)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return
+		}
+		msgs <- string(buf)
+	}
+}
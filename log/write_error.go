@@ -0,0 +1,115 @@
+package log
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// WriteErrorHandler called whenever the log entry failed to be written to the
+// Writer, along with the error returned by the Writer.
+type WriteErrorHandler func(w Writer, err error)
+
+// WithOnWriteError register given fn that is called whenever the log entry
+// failed to be written to any of the Writer(s). Can be applied multiple times.
+// The batch Writer such as HTTP or LOKI never fail to write, so it's called
+// whenever the batch Writer drop the log entries instead.
+func WithOnWriteError(fn WriteErrorHandler) Option {
+	return func(o *options) {
+		o.onWriteErr = append(o.onWriteErr, fn)
+	}
+}
+
+// WriteErrors return the number of log entries that failed to be written to
+// given w by given l, including the ones dropped by the batch Writer. Always return zero if given l is not built by
+// NewZapLogger or NewSlogLogger, or w is not one of its Writer(s).
+func WriteErrors(l Logger, w Writer) uint64 {
+	if c, ok := l.(interface{ writeErrors(w Writer) uint64 }); ok {
+		return c.writeErrors(w)
+	}
+	return 0
+}
+
+// errorWriter io.Writer implementer that count and report the error returned
+// by the Writer.
+type errorWriter struct {
+	w     Writer
+	out   io.Writer
+	hooks []WriteErrorHandler
+	count atomic.Uint64
+}
+
+// newErrorWriter return errorWriter that write to given w and report the error
+// to given hooks. The log entries dropped by w are reported as well, under
+// given owner so the next errorWriter of the same owner replace it.
+func newErrorWriter(owner any, w Writer, hooks []WriteErrorHandler) *errorWriter {
+	e := &errorWriter{w: w, out: w.Writer(), hooks: hooks}
+	if dr := dropReporterOf(w); dr != nil {
+		dr.addDropHook(owner, e.dropped)
+	}
+	return e
+}
+
+func (e *errorWriter) Write(p []byte) (int, error) {
+	n, err := e.out.Write(p)
+	if err != nil {
+		e.count.Add(1)
+		for _, fn := range e.hooks {
+			fn(e.w, err)
+		}
+	}
+	return n, err
+}
+
+// dropped count and report given lines that are dropped by the Writer.
+func (e *errorWriter) dropped(lines [][]byte, err error) {
+	e.count.Add(uint64(len(lines)))
+	for _, fn := range e.hooks {
+		fn(e.w, err)
+	}
+}
+
+// Sync flush the underlying io.Writer if it support it such as os.File.
+func (e *errorWriter) Sync() error {
+	if s, ok := e.out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// writeErrorCounter hold errorWriter of every Writer, so it's shared by the
+// Logger and its child created by With, Group or Ctx.
+type writeErrorCounter []*errorWriter
+
+// writeErrors return the number of failed writes to given w.
+func (c writeErrorCounter) writeErrors(w Writer) uint64 {
+	for _, e := range c {
+		if e.w == w {
+			return e.count.Load()
+		}
+	}
+	return 0
+}
+
+// dropReporter optional interface implemented by the Writer that drop the log
+// entries in the background, such as the batch Writer.
+type dropReporter interface {
+	// addDropHook register given fn that is called with the dropped log
+	// lines, replacing the one previously registered by given key.
+	addDropHook(key any, fn func(lines [][]byte, err error))
+}
+
+// dropReporterOf return the dropReporter of given w by unwrapping it if
+// needed, or nil if there is none.
+func dropReporterOf(w Writer) dropReporter {
+	for w != nil {
+		if dr, ok := w.(dropReporter); ok {
+			return dr
+		}
+		u, ok := w.(interface{ Unwrap() Writer })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+	return nil
+}
@@ -19,3 +19,19 @@ type Writer interface {
 	// Flush any necessary clean up task that will be run by Producer at the last order.
 	Flush(dur time.Duration)
 }
+
+// LevelSetter optional interface that may be implemented by Writer to support
+// changing their Level at runtime. All the pre-defined Writer implement this.
+type LevelSetter interface {
+	// SetLevel change the Writer Level to given lvl.
+	SetLevel(lvl Level)
+}
+
+// HealthReporter optional interface that may be implemented by Writer that
+// deliver the logs in the background, since the delivery error can not be
+// returned by its Write. The batch Writer and NEWRELIC Writer implement this.
+type HealthReporter interface {
+	// LastError return the error of the last failed delivery and when it
+	// happened, or nil error if there is none.
+	LastError() (time.Time, error)
+}
@@ -0,0 +1,218 @@
+package log
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapBufferPool the pool of buffer returned by zapEncoder.
+var zapBufferPool = buffer.NewPool()
+
+// newZapEncoder return zapcore.Encoder that natively encode the log entry
+// using given enc.
+func newZapEncoder(enc Encoder) zapcore.Encoder {
+	enc = enc.withDefault()
+	return &zapEncoder{enc: enc, fieldEncoder: fieldEncoder{logfmt: enc.Format == LogfmtFormat}}
+}
+
+// zapEncoder zapcore.Encoder implementer that encode the log entry using the
+// Encoder. The context fields are encoded once when added, so only the fields
+// of each log entry are encoded on every write.
+type zapEncoder struct {
+	enc Encoder
+	fieldEncoder
+	// ns the number of opened namespaces that should be closed at the end
+	ns int
+}
+
+func (z *zapEncoder) Clone() zapcore.Encoder { return z.clone() }
+func (z *zapEncoder) clone() *zapEncoder {
+	c := *z
+	c.buf = append(make([]byte, 0, len(z.buf)+256), z.buf...)
+	return &c
+}
+
+func (z *zapEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := z.clone()
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	final.closeNamespaces()
+
+	e := encodedEntry{time: ent.Time, level: fromZapLevel(ent.Level), msg: ent.Message, stack: ent.Stack}
+	if ent.Caller.Defined {
+		e.caller, e.function = ent.Caller.TrimmedPath(), ent.Caller.Function
+	}
+	buf := zapBufferPool.Get()
+	buf.Write(z.enc.appendEntry(make([]byte, 0, len(final.buf)+256), &e, final.buf))
+	return buf, nil
+}
+
+// closeNamespaces close every opened namespaces.
+func (z *zapEncoder) closeNamespaces() {
+	for ; z.ns > 0; z.ns-- {
+		z.closeObject("")
+	}
+}
+
+func (z *zapEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	enc := &zapArrayEncoder{buf: []byte{'['}}
+	err := arr.MarshalLogArray(enc)
+	z.addJSON(key, append(enc.buf, ']'))
+	return err
+}
+func (z *zapEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	// the namespaces opened inside the object are closed along with it
+	ns := z.ns
+	prev := z.openObject(key)
+	z.ns = 0
+	err := obj.MarshalLogObject(z)
+	z.closeNamespaces()
+	z.closeObject(prev)
+	z.ns = ns
+	return err
+}
+func (z *zapEncoder) AddBinary(key string, val []byte) {
+	z.addString(key, base64.StdEncoding.EncodeToString(val))
+}
+func (z *zapEncoder) AddByteString(key string, val []byte) { z.addString(key, string(val)) }
+func (z *zapEncoder) AddBool(key string, val bool)         { z.addBool(key, val) }
+func (z *zapEncoder) AddComplex128(key string, val complex128) {
+	z.addString(key, formatComplex(val, 64))
+}
+func (z *zapEncoder) AddComplex64(key string, val complex64) {
+	z.addString(key, formatComplex(complex128(val), 32))
+}
+func (z *zapEncoder) AddDuration(key string, val time.Duration) { z.addString(key, val.String()) }
+func (z *zapEncoder) AddFloat64(key string, val float64)        { z.addFloat(key, val, 64) }
+func (z *zapEncoder) AddFloat32(key string, val float32)        { z.addFloat(key, float64(val), 32) }
+func (z *zapEncoder) AddInt(key string, val int)                { z.addInt(key, int64(val)) }
+func (z *zapEncoder) AddInt64(key string, val int64)            { z.addInt(key, val) }
+func (z *zapEncoder) AddInt32(key string, val int32)            { z.addInt(key, int64(val)) }
+func (z *zapEncoder) AddInt16(key string, val int16)            { z.addInt(key, int64(val)) }
+func (z *zapEncoder) AddInt8(key string, val int8)              { z.addInt(key, int64(val)) }
+func (z *zapEncoder) AddString(key, val string)                 { z.addString(key, val) }
+func (z *zapEncoder) AddTime(key string, val time.Time) {
+	z.addString(key, val.Format(time.RFC3339Nano))
+}
+func (z *zapEncoder) AddUint(key string, val uint)       { z.addUint(key, uint64(val)) }
+func (z *zapEncoder) AddUint64(key string, val uint64)   { z.addUint(key, val) }
+func (z *zapEncoder) AddUint32(key string, val uint32)   { z.addUint(key, uint64(val)) }
+func (z *zapEncoder) AddUint16(key string, val uint16)   { z.addUint(key, uint64(val)) }
+func (z *zapEncoder) AddUint8(key string, val uint8)     { z.addUint(key, uint64(val)) }
+func (z *zapEncoder) AddUintptr(key string, val uintptr) { z.addUint(key, uint64(val)) }
+func (z *zapEncoder) AddReflected(key string, val any) error {
+	b, err := marshalJSON(val)
+	if err != nil {
+		return err
+	}
+	z.addJSON(key, b)
+	return nil
+}
+func (z *zapEncoder) OpenNamespace(key string) {
+	z.openObject(key)
+	if !z.logfmt {
+		z.ns++
+	}
+}
+
+// zapArrayEncoder zapcore.ArrayEncoder implementer that always encode the
+// array as JSON.
+type zapArrayEncoder struct {
+	buf []byte
+}
+
+// sep append the separator from the previous element.
+func (z *zapArrayEncoder) sep() {
+	if len(z.buf) > 1 {
+		z.buf = append(z.buf, ',')
+	}
+}
+func (z *zapArrayEncoder) appendString(val string) {
+	z.sep()
+	z.buf = appendJSONString(z.buf, val)
+}
+
+func (z *zapArrayEncoder) AppendBool(val bool) {
+	z.sep()
+	z.buf = strconv.AppendBool(z.buf, val)
+}
+func (z *zapArrayEncoder) AppendByteString(val []byte) { z.appendString(string(val)) }
+func (z *zapArrayEncoder) AppendComplex128(val complex128) {
+	z.appendString(formatComplex(val, 64))
+}
+func (z *zapArrayEncoder) AppendComplex64(val complex64) {
+	z.appendString(formatComplex(complex128(val), 32))
+}
+func (z *zapArrayEncoder) AppendFloat64(val float64) { z.appendFloat(val, 64) }
+func (z *zapArrayEncoder) AppendFloat32(val float32) { z.appendFloat(float64(val), 32) }
+func (z *zapArrayEncoder) appendFloat(val float64, bits int) {
+	if s, ok := nonFiniteFloat(val); ok {
+		z.appendString(s)
+		return
+	}
+	z.sep()
+	z.buf = appendJSONFloat(z.buf, val, bits)
+}
+func (z *zapArrayEncoder) AppendInt(val int)     { z.AppendInt64(int64(val)) }
+func (z *zapArrayEncoder) AppendInt32(val int32) { z.AppendInt64(int64(val)) }
+func (z *zapArrayEncoder) AppendInt16(val int16) { z.AppendInt64(int64(val)) }
+func (z *zapArrayEncoder) AppendInt8(val int8)   { z.AppendInt64(int64(val)) }
+func (z *zapArrayEncoder) AppendInt64(val int64) {
+	z.sep()
+	z.buf = strconv.AppendInt(z.buf, val, 10)
+}
+func (z *zapArrayEncoder) AppendString(val string)   { z.appendString(val) }
+func (z *zapArrayEncoder) AppendUint(val uint)       { z.AppendUint64(uint64(val)) }
+func (z *zapArrayEncoder) AppendUint32(val uint32)   { z.AppendUint64(uint64(val)) }
+func (z *zapArrayEncoder) AppendUint16(val uint16)   { z.AppendUint64(uint64(val)) }
+func (z *zapArrayEncoder) AppendUint8(val uint8)     { z.AppendUint64(uint64(val)) }
+func (z *zapArrayEncoder) AppendUintptr(val uintptr) { z.AppendUint64(uint64(val)) }
+func (z *zapArrayEncoder) AppendUint64(val uint64) {
+	z.sep()
+	z.buf = strconv.AppendUint(z.buf, val, 10)
+}
+func (z *zapArrayEncoder) AppendDuration(val time.Duration) { z.appendString(val.String()) }
+func (z *zapArrayEncoder) AppendTime(val time.Time) {
+	z.appendString(val.Format(time.RFC3339Nano))
+}
+func (z *zapArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	enc := &zapArrayEncoder{buf: []byte{'['}}
+	err := arr.MarshalLogArray(enc)
+	z.sep()
+	z.buf = append(append(z.buf, enc.buf...), ']')
+	return err
+}
+func (z *zapArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	// the object inside the array is always JSON even for logfmt
+	enc := &zapEncoder{}
+	err := obj.MarshalLogObject(enc)
+	enc.closeNamespaces()
+	z.sep()
+	z.buf = append(append(append(z.buf, '{'), enc.buf...), '}')
+	return err
+}
+func (z *zapArrayEncoder) AppendReflected(val any) error {
+	b, err := marshalJSON(val)
+	if err != nil {
+		return err
+	}
+	z.sep()
+	z.buf = append(z.buf, b...)
+	return nil
+}
+
+// formatComplex return given c as string such as '1+2i'.
+func formatComplex(c complex128, bits int) string {
+	r, i := real(c), imag(c)
+	b := strconv.AppendFloat(nil, r, 'f', -1, bits)
+	if i >= 0 {
+		b = append(b, '+')
+	}
+	b = strconv.AppendFloat(b, i, 'f', -1, bits)
+	return string(append(b, 'i'))
+}
@@ -1,6 +1,8 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
@@ -9,14 +11,22 @@ import (
 
 // NewZapLogger return Logger implementer that use zap as the backend.
 func NewZapLogger(wr ...Writer) Logger {
-	// set to singleton instead
-	singletonLogger = &zapLogger{wr: wr}
-	return singletonLogger
+	return NewZapLoggerWithOptions(wr)
+}
+
+// NewZapLoggerWithOptions return Logger implementer that use zap as the
+// backend after applying given opts.
+func NewZapLoggerWithOptions(wr []Writer, opts ...Option) Logger {
+	return &zapLogger{wr: wr, opt: newOptions(opts...)}
 }
 
 type zapLogger struct {
 	log *zap.Logger
 	wr  []Writer
+	opt *options
+	smp []*samplingReporter
+	err writeErrorCounter
+	dur time.Duration
 }
 
 func (z *zapLogger) clone() *zapLogger {
@@ -24,32 +34,78 @@ func (z *zapLogger) clone() *zapLogger {
 	return &c
 }
 func (z *zapLogger) Init(dur time.Duration) {
+	// keep it, so the Writer(s) can be flushed on PanicLevel and FatalLevel
+	z.dur = dur
+
 	var cores []zapcore.Core
 	// setup common zap json encoder
 	jsonEnc := zap.NewProductionEncoderConfig()
 	jsonEnc.EncodeTime = zapcore.RFC3339TimeEncoder
-	jsonEnc.EncodeLevel = zapcore.CapitalLevelEncoder
+	jsonEnc.EncodeLevel = zapCapitalLevelEncoder
 	jsonEnc.TimeKey = "time"
+	jsonEnc.FunctionKey = "function"
+	jsonEnc.EncodeDuration = zapcore.StringDurationEncoder
 
+	z.err = nil
 	for _, w := range z.wr {
-		switch w.Output() {
-		case CONSOLE:
-			encCnf := zap.NewDevelopmentConfig().EncoderConfig
-			encCnf.EncodeLevel = zapcore.CapitalColorLevelEncoder
-			enc := zapcore.NewConsoleEncoder(encCnf)
-			core := zapcore.NewCore(enc, zapcore.AddSync(w.Writer()), toZapLevel(w.Level()))
-			cores = append(cores, core)
-
-		case FILE, NEWRELIC:
-			enc := zapcore.NewJSONEncoder(jsonEnc)
-			core := zapcore.NewCore(enc, zapcore.AddSync(w.Writer()), toZapLevel(w.Level()))
-			cores = append(cores, core)
+		ew := newErrorWriter(z.opt, w, z.opt.onWriteErr)
+		z.err = append(z.err, ew)
+		enc := zapcore.NewJSONEncoder(jsonEnc)
+		if e := z.opt.encoderFor(w); e != nil {
+			enc = newZapEncoder(*e)
 		}
+		core := zapcore.NewCore(enc, zapcore.AddSync(ew), zapLevelEnabler(w))
+		cores = append(cores, z.wrapCore(w, core))
 		w.Wait(dur)
 	}
-	z.log = zap.New(zapcore.NewTee(cores...))
+	z.log = zap.New(zapcore.NewTee(cores...), z.zapOptions()...)
+}
+
+// zapOptions return zap options based on the applied options.
+func (z *zapLogger) zapOptions() []zap.Option {
+	// always skip the zapLogger method itself
+	opts := []zap.Option{
+		zap.AddCallerSkip(1 + z.opt.callerSkip),
+		zap.WithPanicHook(zapTerminateHook{z}),
+		zap.WithFatalHook(zapTerminateHook{z}),
+	}
+	if z.opt.caller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if z.opt.stack {
+		opts = append(opts, zap.AddStacktrace(toZapLevel(z.opt.stackLvl)))
+	}
+	return opts
+}
+
+// wrapCore wrap given core based on the options that applied to given w.
+func (z *zapLogger) wrapCore(w Writer, core zapcore.Core) zapcore.Core {
+	if rt := routerOf(w); rt != nil {
+		core = &zapRouteCore{Core: core, rt: rt}
+	}
+	if rd := z.opt.redactorFor(w); rd != nil {
+		core = &zapRedactCore{Core: core, rd: rd}
+	}
+	if cnf := z.opt.samplingFor(w); cnf != nil {
+		smp, inner := newSampler(*cnf), core
+		z.smp = append(z.smp, newSamplingReporter(smp, func(dropped uint64) {
+			// only report to Writer that currently accept WARN level
+			if !inner.Enabled(zapcore.WarnLevel) {
+				return
+			}
+			ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: samplingSummaryMsg}
+			inner.Write(ent, []zapcore.Field{zap.Uint64("dropped", dropped), zap.Duration("tick", smp.cnf.Tick)})
+		}))
+		core = &zapSamplingCore{Core: core, smp: smp}
+	}
+	return core
 }
+func (z *zapLogger) enabled(lvl Level) bool      { return z.log.Core().Enabled(toZapLevel(lvl)) }
+func (z *zapLogger) writeErrors(w Writer) uint64 { return z.err.writeErrors(w) }
 func (z *zapLogger) Flush(dur time.Duration) {
+	for _, r := range z.smp {
+		r.Stop()
+	}
 	for _, w := range z.wr {
 		w.Flush(dur)
 	}
@@ -59,25 +115,15 @@ func (z *zapLogger) With(pr ...Log) Logger {
 	if len(pr) == 0 {
 		return z
 	}
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	// clone it, so on every With method call does not affect the parent logger
 	clone := z.clone()
 	clone.log = clone.log.With(toZapFields(pr)...)
-
-	// then reassign to singleton
-	singletonLogger = clone
-
 	return clone
 }
 func (z *zapLogger) Group(key string, pr ...Log) Logger {
 	if len(pr) == 0 || key == "" {
 		return z
 	}
-	mutex.Lock()
-	defer mutex.Unlock()
-
 	// clone it, so on every With method call does not affect the parent logger
 	clone := z.clone()
 	// for zap, the trick is to use Any instead of Namespace, because as the docs said
@@ -91,12 +137,18 @@ func (z *zapLogger) Group(key string, pr ...Log) Logger {
 	//  notice that 'ctx' is embedded as 'usecase_layer' field when using Namespace, but
 	//   it's properly wrapped as intended when using Any
 	clone.log = clone.log.With(zap.Any(key, toZapFields(pr)))
-
-	// then reassign to singleton
-	singletonLogger = clone
-
 	return clone
 }
+func (z *zapLogger) Ctx(ctx context.Context) Logger {
+	return z.With(ctxFields(ctx, z.opt.extractors)...)
+}
+func (z *zapLogger) Trc(msg string, pr ...Log) {
+	if len(pr) > 0 {
+		z.log.Log(zapTraceLevel, msg, toZapFields(pr)...)
+		return
+	}
+	z.log.Log(zapTraceLevel, msg)
+}
 func (z *zapLogger) Dbg(msg string, pr ...Log) {
 	if len(pr) > 0 {
 		z.log.Debug(msg, toZapFields(pr)...)
@@ -125,10 +177,57 @@ func (z *zapLogger) Err(msg string, pr ...Log) {
 	}
 	z.log.Error(msg)
 }
+func (z *zapLogger) Pnc(msg string, pr ...Log) {
+	if len(pr) > 0 {
+		z.log.Panic(msg, toZapFields(pr)...)
+		return
+	}
+	z.log.Panic(msg)
+}
+func (z *zapLogger) Ftl(msg string, pr ...Log) {
+	if len(pr) > 0 {
+		z.log.Fatal(msg, toZapFields(pr)...)
+		return
+	}
+	z.log.Fatal(msg)
+}
+
+// logRecord log given msg and pr at given lvl with given time and the caller
+// of given pc instead of the current ones. Zero t and pc are ignored.
+func (z *zapLogger) logRecord(lvl Level, t time.Time, pc uintptr, msg string, pr []Log) {
+	ce := z.log.Check(toZapLevel(lvl), msg)
+	if ce == nil {
+		return
+	}
+	if !t.IsZero() {
+		ce.Time = t
+	}
+	if frame, ok := frameOf(pc); ok && ce.Caller.Defined {
+		ce.Caller = zapcore.NewEntryCaller(pc, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	ce.Write(toZapFields(pr)...)
+}
+
+// zapTerminateHook zapcore.CheckWriteHook implementer that flush all the
+// Writer(s) before panics or exit the process.
+type zapTerminateHook struct {
+	z *zapLogger
+}
+
+func (h zapTerminateHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	terminate(fromZapLevel(ce.Level), ce.Message, func() { h.z.Flush(h.z.dur) })
+}
+
+// zapTraceLevel zap does not have trace level, so use the level right below
+// the zap debug level.
+const zapTraceLevel = zapcore.DebugLevel - 1
 
 // toZapLevel transform local log Level to zap level.
 func toZapLevel(lvl Level) zapcore.Level {
 	switch lvl {
+	case TraceLevel:
+		return zapTraceLevel
 	case DebugLevel:
 		return zapcore.DebugLevel
 	case InfoLevel:
@@ -137,10 +236,51 @@ func toZapLevel(lvl Level) zapcore.Level {
 		return zapcore.WarnLevel
 	case ErrorLevel:
 		return zapcore.ErrorLevel
+	case PanicLevel:
+		return zapcore.PanicLevel
+	case FatalLevel:
+		return zapcore.FatalLevel
 	}
 	return zapcore.InvalidLevel
 }
 
+// fromZapLevel transform zap level to local log Level.
+func fromZapLevel(lvl zapcore.Level) Level {
+	switch lvl {
+	case zapTraceLevel:
+		return TraceLevel
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.PanicLevel:
+		return PanicLevel
+	case zapcore.FatalLevel:
+		return FatalLevel
+	}
+	return ErrorLevel
+}
+
+// zapCapitalLevelEncoder same as zapcore.CapitalLevelEncoder, but encode the
+// trace level as TRACE.
+func zapCapitalLevelEncoder(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if lvl == zapTraceLevel {
+		enc.AppendString(TraceLevel.String())
+		return
+	}
+	zapcore.CapitalLevelEncoder(lvl, enc)
+}
+
+// zapLevelEnabler return zap level enabler that always read the current Level
+// of given Writer, so any changes to the Writer Level at runtime is respected.
+func zapLevelEnabler(w Writer) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= toZapLevel(w.Level())
+	})
+}
+
 // toZapFields transform local Log to zap field.
 func toZapFields(pr []Log) []zapcore.Field {
 	var fields []zapcore.Field
@@ -158,7 +298,146 @@ func toZapFields(pr []Log) []zapcore.Field {
 			fields = append(fields, zap.Any(p.key, p.any))
 		case ErrorType:
 			fields = append(fields, zap.NamedError(p.key, p.err))
+		case DurationType:
+			fields = append(fields, zap.Duration(p.key, time.Duration(p.i64)))
+		case TimeType:
+			fields = append(fields, zap.Time(p.key, p.any.(time.Time)))
+		case Int64Type:
+			fields = append(fields, zap.Int64(p.key, p.i64))
+		case Uint64Type:
+			fields = append(fields, zap.Uint64(p.key, p.u64))
+		case StringsType:
+			fields = append(fields, zap.Strings(p.key, p.any.([]string)))
+		case IntsType:
+			fields = append(fields, zap.Ints(p.key, p.any.([]int)))
+		case StringerType:
+			fields = append(fields, zap.Stringer(p.key, p.any.(fmt.Stringer)))
+		case BytesType:
+			fields = append(fields, zap.ByteString(p.key, p.any.([]byte)))
+		case BinaryType:
+			fields = append(fields, zap.Binary(p.key, p.any.([]byte)))
+		case NamespaceType:
+			fields = append(fields, zap.Dict(p.key, toZapFields(p.any.([]Log))...))
 		}
 	}
 	return fields
 }
+
+// zapSamplingCore zapcore.Core implementer that sample the log entries before
+// passing it to the underlying core.
+type zapSamplingCore struct {
+	zapcore.Core
+	smp *sampler
+}
+
+func (z *zapSamplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapSamplingCore{Core: z.Core.With(fields), smp: z.smp}
+}
+func (z *zapSamplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !z.Enabled(ent.Level) || !z.smp.sample(fromZapLevel(ent.Level), ent.Message) {
+		return ce
+	}
+	return z.Core.Check(ent, ce)
+}
+
+// zapRouteCore zapcore.Core implementer that only pass the log entries that
+// match the RoutedWriter to the underlying core.
+type zapRouteCore struct {
+	zapcore.Core
+	rt *RoutedWriter
+	// fields the accumulated fields, so the filter can match it as well
+	fields []zapcore.Field
+}
+
+func (z *zapRouteCore) Enabled(lvl zapcore.Level) bool {
+	return z.rt.enabled(fromZapLevel(lvl)) && z.Core.Enabled(lvl)
+}
+func (z *zapRouteCore) With(fields []zapcore.Field) zapcore.Core {
+	acc := append(append([]zapcore.Field{}, z.fields...), fields...)
+	return &zapRouteCore{Core: z.Core.With(fields), rt: z.rt, fields: acc}
+}
+func (z *zapRouteCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if z.Enabled(ent.Level) {
+		return ce.AddCore(ent, z)
+	}
+	return ce
+}
+func (z *zapRouteCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	e := Entry{Level: fromZapLevel(ent.Level), Message: ent.Message, field: func(key string) (any, bool) {
+		// the latest field take precedence
+		for _, fs := range [][]zapcore.Field{fields, z.fields} {
+			for i := len(fs) - 1; i >= 0; i-- {
+				if fs[i].Key == key {
+					enc := zapcore.NewMapObjectEncoder()
+					fs[i].AddTo(enc)
+					return fieldValue(enc.Fields[key]), true
+				}
+			}
+		}
+		return nil, false
+	}}
+	if !z.rt.match(e) {
+		return nil
+	}
+	return z.Core.Write(ent, fields)
+}
+
+// zapRedactCore zapcore.Core implementer that redact the sensitive fields
+// before passing it to the underlying core.
+type zapRedactCore struct {
+	zapcore.Core
+	rd *redactor
+}
+
+func (z *zapRedactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapRedactCore{Core: z.Core.With(z.redact(fields)), rd: z.rd}
+}
+func (z *zapRedactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if z.Enabled(ent.Level) {
+		return ce.AddCore(ent, z)
+	}
+	return ce
+}
+func (z *zapRedactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return z.Core.Write(ent, z.redact(fields))
+}
+
+// redact return copy of given fields with any sensitive value redacted.
+func (z *zapRedactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var res []zapcore.Field
+	for i, f := range fields {
+		var v any
+		switch f.Type {
+		case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType, zapcore.InlineMarshalerType:
+			// composite value, need to be traversed
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			v = enc.Fields[f.Key]
+			if f.Type == zapcore.InlineMarshalerType {
+				v = enc.Fields
+			}
+		default:
+			if _, ok := z.rd.match([]string{f.Key}); !ok {
+				continue
+			}
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			v = enc.Fields[f.Key]
+		}
+
+		nv, changed := z.rd.redact([]string{f.Key}, v)
+		if !changed {
+			continue
+		}
+		if res == nil {
+			// copy on write
+			res = make([]zapcore.Field, len(fields))
+			copy(res, fields)
+		}
+		res[i] = zap.Any(f.Key, nv)
+	}
+	if res == nil {
+		return fields
+	}
+	return res
+}
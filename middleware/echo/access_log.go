@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdanialr/api-pkg-go/helper"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+const (
+	// codeCaptureSize the size of the response body that is kept to read the
+	// App.Code, which is enough since it's the first field.
+	codeCaptureSize = 1 << 10
+	// bodyCaptureSize the maximum size of the response body that is kept when
+	// it's sampled.
+	bodyCaptureSize = 64 << 10
+)
+
+// AccessLogOpt an option signature for AccessLog.
+type AccessLogOpt func(*accessLog)
+
+// WithSkipPaths do not log the request to given paths such as the health
+// check.
+func WithSkipPaths(paths ...string) AccessLogOpt {
+	return func(a *accessLog) {
+		for _, p := range paths {
+			a.skip[p] = struct{}{}
+		}
+	}
+}
+
+// WithBodySampling capture the request and response JSON body of given rate
+// of the requests, from 0 which is never to 1 which is always. Default to 0.
+func WithBodySampling(rate float64) AccessLogOpt {
+	return func(a *accessLog) {
+		a.rate = rate
+	}
+}
+
+type accessLog struct {
+	skip map[string]struct{}
+	rate float64
+}
+
+// AccessLog return echo framework middleware that log every request through
+// log.FromCtx with the method, route, status, latency, bytes, client ip, user
+// agent and the response App.Code if any. The request with 5xx status is
+// logged at ErrorLevel, 4xx at WarnLevel and the rest at InfoLevel. The error
+// returned by the next handler is passed to the echo HTTPErrorHandler first,
+// so the logged status is the one that is sent.
+//
+//	e.Use(middleware.AccessLog(middleware.WithSkipPaths("/health")))
+func AccessLog(opts ...AccessLogOpt) echo.MiddlewareFunc {
+	a := &accessLog{skip: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := a.skip[c.Request().URL.Path]; ok {
+				return next(c)
+			}
+
+			start := time.Now()
+			var req map[string]any
+			capture := a.rate > 0 && rand.Float64() < a.rate
+			if capture {
+				req = helper.ExtractRequest(c.Request())
+			}
+			res := c.Response()
+			bw := &bodyWriter{ResponseWriter: res.Writer, limit: codeCaptureSize}
+			if capture {
+				bw.limit = bodyCaptureSize
+			}
+			res.Writer = bw
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = bw.ResponseWriter
+
+			pr := []log.Log{
+				log.String("method", c.Request().Method),
+				log.String("route", c.Path()),
+				log.Num("status", res.Status),
+				log.Duration("latency", time.Since(start)),
+				log.Int64("bytes", res.Size),
+				log.String("ip", c.RealIP()),
+				log.String("user_agent", c.Request().UserAgent()),
+			}
+			if code := helper.ResponseCode(bw.buf.Bytes()); code != "" {
+				pr = append(pr, log.String("code", code))
+			}
+			if capture {
+				pr = append(pr, log.Any("request", req), log.Any("response", helper.ExtractResponse(bw.buf.Bytes())))
+			}
+
+			l := log.FromCtx(c.Request().Context())
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				l.Err("access", pr...)
+			case res.Status >= http.StatusBadRequest:
+				l.Wrn("access", pr...)
+			default:
+				l.Inf("access", pr...)
+			}
+			return nil
+		}
+	}
+}
+
+// bodyWriter http.ResponseWriter implementer that keep the response body up
+// to the limit.
+type bodyWriter struct {
+	http.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *bodyWriter) Write(p []byte) (int, error) {
+	if n := b.limit - b.buf.Len(); n > 0 {
+		b.buf.Write(p[:min(n, len(p))])
+	}
+	return b.ResponseWriter.Write(p)
+}
+
+// Unwrap return the underlying http.ResponseWriter for http.ResponseController.
+func (b *bodyWriter) Unwrap() http.ResponseWriter { return b.ResponseWriter }
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.DebugLevel, log.FILE)
+	wr := log.NewSlogLogger(w)
+	wr.Init(time.Microsecond)
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(log.WithCtx(context.Background(), wr)))
+			return next(c)
+		}
+	})
+	e.Use(AccessLog(WithSkipPaths("/health"), WithBodySampling(1)))
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.POST("/users/:id", func(c echo.Context) error {
+		var body map[string]any
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, echo.Map{"code": "USR-201", "message": "Ok", "data": echo.Map{"id": c.Param("id")}})
+	})
+	e.GET("/boom", func(c echo.Context) error { return errors.New("boom") })
+
+	testCases := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantLog   bool
+		wantLevel log.Level
+		wantRoute string
+		wantCode  any
+	}{
+		{name: "skip path", method: http.MethodGet, path: "/health"},
+		{name: "success", method: http.MethodPost, path: "/users/7", body: `{"name":"kpm"}`, wantLog: true, wantLevel: log.InfoLevel, wantRoute: "/users/:id", wantCode: "USR-201"},
+		{name: "client error", method: http.MethodGet, path: "/nope", wantLog: true, wantLevel: log.WarnLevel},
+		{name: "server error", method: http.MethodGet, path: "/boom", wantLog: true, wantLevel: log.ErrorLevel, wantRoute: "/boom"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("User-Agent", "test")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			got := obs.TakeAll()
+			if !tc.wantLog {
+				assert.Empty(t, got)
+				return
+			}
+			require.Len(t, got, 1)
+			assert.True(t, got[0].EqualMsg("access"))
+			assert.True(t, got[0].EqualLevel(tc.wantLevel))
+			assert.Equal(t, tc.method, got[0].Get("method"))
+			assert.Equal(t, tc.wantRoute, got[0].Get("route"))
+			assert.Equal(t, "test", got[0].Get("user_agent"))
+			assert.Equal(t, float64(rec.Body.Len()), got[0].Get("bytes"))
+			assert.Equal(t, tc.wantCode, got[0].Get("code"))
+			if tc.body != "" {
+				assert.Equal(t, map[string]any{"name": "kpm"}, got[0].Get("request"))
+				assert.Equal(t, "USR-201", got[0].Get("response").(map[string]any)["code"])
+			}
+		})
+	}
+}
+
+func TestBodyWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	bw := &bodyWriter{ResponseWriter: rec, limit: 4}
+	bw.Write([]byte("abc"))
+	bw.Write([]byte("def"))
+	assert.Equal(t, "abcd", bw.buf.String())
+	assert.Equal(t, "abcdef", rec.Body.String())
+}
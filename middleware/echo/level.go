@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+// LevelHandler return echo framework handler that read and change the Level
+// of named log.Writer at runtime. Mount it for both GET and PUT method.
+//
+//	lh := log.NewLevelHandler(cns, fl)
+//	e.GET("/log/level", middleware.LevelHandler(lh))
+//	e.PUT("/log/level", middleware.LevelHandler(lh))
+func LevelHandler(h *log.LevelHandler) echo.HandlerFunc {
+	return echo.WrapHandler(h)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	cns := log.NewConsoleWriter(log.InfoLevel)
+	e := echo.New()
+	e.PUT("/log/level", LevelHandler(log.NewLevelHandler(cns)))
+
+	req := httptest.NewRequest(http.MethodPut, "/log/level?writer=console", strings.NewReader(`{"level":"debug"}`))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"writer":"console","level":"DEBUG"}`, rec.Body.String())
+	assert.Equal(t, log.DebugLevel, cns.Level())
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	gommon "github.com/labstack/gommon/log"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+// NewLogger return echo.Logger that write logs through given l, so the logs
+// of echo framework itself go through the same Writer(s). The level is
+// default to DEBUG, so only the Writer(s) Level decide. SetOutput and
+// SetHeader have no effect on the logs since the Writer(s) decide where and
+// how the logs are written.
+//
+//	e := echo.New()
+//	e.Logger = middleware.NewLogger(wr)
+func NewLogger(l log.Logger) echo.Logger {
+	return &echoLogger{l: l, lvl: gommon.DEBUG}
+}
+
+type echoLogger struct {
+	l      log.Logger
+	lvl    gommon.Lvl
+	prefix string
+	out    io.Writer
+}
+
+// Output return the io.Writer set by SetOutput, or the one that write every
+// line through the Logger at InfoLevel.
+func (e *echoLogger) Output() io.Writer {
+	if e.out != nil {
+		return e.out
+	}
+	return &lineWriter{e: e}
+}
+func (e *echoLogger) SetOutput(w io.Writer) { e.out = w }
+func (e *echoLogger) Prefix() string        { return e.prefix }
+func (e *echoLogger) SetPrefix(p string)    { e.prefix = p }
+func (e *echoLogger) Level() gommon.Lvl     { return e.lvl }
+func (e *echoLogger) SetLevel(v gommon.Lvl) { e.lvl = v }
+func (e *echoLogger) SetHeader(_ string)    {}
+
+func (e *echoLogger) Print(i ...interface{})            { e.log(gommon.INFO, fmt.Sprint(i...)) }
+func (e *echoLogger) Printf(f string, a ...interface{}) { e.log(gommon.INFO, fmt.Sprintf(f, a...)) }
+func (e *echoLogger) Printj(j gommon.JSON)              { e.log(gommon.INFO, "", fromJSON(j)...) }
+func (e *echoLogger) Debug(i ...interface{})            { e.log(gommon.DEBUG, fmt.Sprint(i...)) }
+func (e *echoLogger) Debugf(f string, a ...interface{}) { e.log(gommon.DEBUG, fmt.Sprintf(f, a...)) }
+func (e *echoLogger) Debugj(j gommon.JSON)              { e.log(gommon.DEBUG, "", fromJSON(j)...) }
+func (e *echoLogger) Info(i ...interface{})             { e.log(gommon.INFO, fmt.Sprint(i...)) }
+func (e *echoLogger) Infof(f string, a ...interface{})  { e.log(gommon.INFO, fmt.Sprintf(f, a...)) }
+func (e *echoLogger) Infoj(j gommon.JSON)               { e.log(gommon.INFO, "", fromJSON(j)...) }
+func (e *echoLogger) Warn(i ...interface{})             { e.log(gommon.WARN, fmt.Sprint(i...)) }
+func (e *echoLogger) Warnf(f string, a ...interface{})  { e.log(gommon.WARN, fmt.Sprintf(f, a...)) }
+func (e *echoLogger) Warnj(j gommon.JSON)               { e.log(gommon.WARN, "", fromJSON(j)...) }
+func (e *echoLogger) Error(i ...interface{})            { e.log(gommon.ERROR, fmt.Sprint(i...)) }
+func (e *echoLogger) Errorf(f string, a ...interface{}) { e.log(gommon.ERROR, fmt.Sprintf(f, a...)) }
+func (e *echoLogger) Errorj(j gommon.JSON)              { e.log(gommon.ERROR, "", fromJSON(j)...) }
+
+// the panic and fatal level are never disabled the same way as echo
+func (e *echoLogger) Fatal(i ...interface{})            { e.l.Ftl(fmt.Sprint(i...), e.with()...) }
+func (e *echoLogger) Fatalj(j gommon.JSON)              { e.l.Ftl("", e.with(fromJSON(j)...)...) }
+func (e *echoLogger) Fatalf(f string, a ...interface{}) { e.l.Ftl(fmt.Sprintf(f, a...), e.with()...) }
+func (e *echoLogger) Panic(i ...interface{})            { e.l.Pnc(fmt.Sprint(i...), e.with()...) }
+func (e *echoLogger) Panicj(j gommon.JSON)              { e.l.Pnc("", e.with(fromJSON(j)...)...) }
+func (e *echoLogger) Panicf(f string, a ...interface{}) { e.l.Pnc(fmt.Sprintf(f, a...), e.with()...) }
+
+// log write given msg and pr through the Logger if given lvl is enabled.
+func (e *echoLogger) log(lvl gommon.Lvl, msg string, pr ...log.Log) {
+	if lvl < e.lvl {
+		return
+	}
+	pr = e.with(pr...)
+	switch lvl {
+	case gommon.DEBUG:
+		e.l.Dbg(msg, pr...)
+	case gommon.INFO:
+		e.l.Inf(msg, pr...)
+	case gommon.WARN:
+		e.l.Wrn(msg, pr...)
+	case gommon.ERROR:
+		e.l.Err(msg, pr...)
+	}
+}
+
+// with return given pr along with the prefix if any.
+func (e *echoLogger) with(pr ...log.Log) []log.Log {
+	if e.prefix != "" {
+		pr = append(pr, log.String("prefix", e.prefix))
+	}
+	return pr
+}
+
+// fromJSON transform given j to log.Log sorted by the key.
+func fromJSON(j gommon.JSON) []log.Log {
+	keys := make([]string, 0, len(j))
+	for k := range j {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pr := make([]log.Log, len(keys))
+	for i, k := range keys {
+		pr[i] = log.Any(k, j[k])
+	}
+	return pr
+}
+
+// lineWriter io.Writer implementer that write every line through the
+// echoLogger at INFO level.
+type lineWriter struct {
+	e *echoLogger
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.e.log(gommon.INFO, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gommon "github.com/labstack/gommon/log"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.DebugLevel, log.FILE)
+	wr := log.NewZapLogger(w)
+	wr.Init(time.Microsecond)
+
+	el := NewLogger(wr)
+	el.Debug("debug ", 1)
+	el.SetLevel(gommon.INFO)
+	el.Debugf("filtered")
+	el.SetPrefix("echo")
+	el.Infof("listening on %s", ":8080")
+	el.Warnj(gommon.JSON{"b": 2, "a": "x"})
+	el.Errorf("oops")
+	fmt.Fprintln(el.Output(), "⇨ http server started")
+	assert.Panics(t, func() { el.Panic("boom") })
+
+	got := obs.All()
+	require.Len(t, got, 6)
+	assert.True(t, got[0].EqualMsg("debug 1"))
+	assert.True(t, got[0].EqualLevel(log.DebugLevel))
+	assert.True(t, got[1].EqualMsg("listening on :8080"))
+	assert.Equal(t, "echo", got[1].Get("prefix"))
+	assert.True(t, got[2].EqualLevel(log.WarnLevel))
+	assert.Equal(t, "x", got[2].Get("a"))
+	assert.Equal(t, float64(2), got[2].Get("b"))
+	assert.True(t, got[3].EqualLevel(log.ErrorLevel))
+	assert.True(t, got[4].EqualMsg("⇨ http server started"))
+	assert.True(t, got[5].EqualLevel(log.PanicLevel))
+	assert.Equal(t, gommon.INFO, el.Level())
+	assert.Equal(t, "echo", el.Prefix())
+}
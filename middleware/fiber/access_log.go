@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/mdanialr/api-pkg-go/helper"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+const (
+	// codeCaptureSize the size of the response body that is read for the
+	// App.Code, which is enough since it's the first field.
+	codeCaptureSize = 1 << 10
+	// bodyCaptureSize the maximum size of the response body that is logged
+	// when it's sampled.
+	bodyCaptureSize = 64 << 10
+)
+
+// AccessLogOpt an option signature for AccessLog.
+type AccessLogOpt func(*accessLog)
+
+// WithSkipPaths do not log the request to given paths such as the health
+// check.
+func WithSkipPaths(paths ...string) AccessLogOpt {
+	return func(a *accessLog) {
+		for _, p := range paths {
+			a.skip[p] = struct{}{}
+		}
+	}
+}
+
+// WithBodySampling capture the request and response JSON body of given rate
+// of the requests, from 0 which is never to 1 which is always. Default to 0.
+func WithBodySampling(rate float64) AccessLogOpt {
+	return func(a *accessLog) {
+		a.rate = rate
+	}
+}
+
+type accessLog struct {
+	skip map[string]struct{}
+	rate float64
+}
+
+// AccessLog return fiber framework middleware that log every request through
+// log.FromCtx with the method, route, status, latency, bytes, client ip, user
+// agent and the response App.Code if any. The request with 5xx status is
+// logged at ErrorLevel, 4xx at WarnLevel and the rest at InfoLevel. The error
+// returned by the next handler is passed to the app ErrorHandler first, so
+// the logged status is the one that is sent.
+//
+//	app.Use(middleware.AccessLog(middleware.WithSkipPaths("/health")))
+func AccessLog(opts ...AccessLogOpt) fiber.Handler {
+	a := &accessLog{skip: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return func(c *fiber.Ctx) error {
+		if _, ok := a.skip[c.Path()]; ok {
+			return c.Next()
+		}
+
+		start := time.Now()
+		var req map[string]any
+		capture := a.rate > 0 && rand.Float64() < a.rate
+		if capture {
+			if r, err := adaptor.ConvertRequest(c, false); err == nil {
+				req = helper.ExtractRequest(r)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			if err = c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		res := c.Response()
+		status := res.StatusCode()
+		// reading the body stream such as SendStream and SendFile drain it, so
+		// only the buffered body is read and the size of the stream is taken
+		// from the Content-Length if it's known
+		var body []byte
+		size := max(res.Header.ContentLength(), 0)
+		if !res.IsBodyStream() {
+			body = res.Body()
+			size = len(body)
+		}
+		pr := []log.Log{
+			log.String("method", c.Method()),
+			log.String("route", c.Route().Path),
+			log.Num("status", status),
+			log.Duration("latency", time.Since(start)),
+			log.Num("bytes", size),
+			log.String("ip", c.IP()),
+			log.String("user_agent", c.Get(fiber.HeaderUserAgent)),
+		}
+		if code := helper.ResponseCode(body[:min(len(body), codeCaptureSize)]); code != "" {
+			pr = append(pr, log.String("code", code))
+		}
+		if capture {
+			// the body over the limit is truncated, so it's logged as {}
+			b := body[:min(len(body), bodyCaptureSize)]
+			pr = append(pr, log.Any("request", req), log.Any("response", helper.ExtractResponse(b)))
+		}
+
+		l := log.FromCtx(c.UserContext())
+		switch {
+		case status >= http.StatusInternalServerError:
+			l.Err("access", pr...)
+		case status >= http.StatusBadRequest:
+			l.Wrn("access", pr...)
+		default:
+			l.Inf("access", pr...)
+		}
+		return nil
+	}
+}
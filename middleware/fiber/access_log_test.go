@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.DebugLevel, log.FILE)
+	wr := log.NewZapLogger(w)
+	wr.Init(time.Microsecond)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(log.WithCtx(context.Background(), wr))
+		return c.Next()
+	})
+	app.Use(AccessLog(WithSkipPaths("/health"), WithBodySampling(1)))
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+	app.Post("/users/:id", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"code": "USR-201", "message": "Ok", "data": fiber.Map{"id": c.Params("id")}})
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error { return errors.New("boom") })
+
+	testCases := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantLog   bool
+		wantLevel log.Level
+		wantRoute string
+		wantCode  any
+	}{
+		{name: "skip path", method: http.MethodGet, path: "/health"},
+		{name: "success", method: http.MethodPost, path: "/users/7", body: `{"name":"kpm"}`, wantLog: true, wantLevel: log.InfoLevel, wantRoute: "/users/:id", wantCode: "USR-201"},
+		{name: "client error", method: http.MethodGet, path: "/nope", wantLog: true, wantLevel: log.WarnLevel, wantRoute: "/"},
+		{name: "server error", method: http.MethodGet, path: "/boom", wantLog: true, wantLevel: log.ErrorLevel, wantRoute: "/boom"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set(fiber.HeaderUserAgent, "test")
+			_, err := app.Test(req)
+			require.NoError(t, err)
+
+			got := obs.TakeAll()
+			if !tc.wantLog {
+				assert.Empty(t, got)
+				return
+			}
+			require.Len(t, got, 1)
+			assert.True(t, got[0].EqualMsg("access"))
+			assert.True(t, got[0].EqualLevel(tc.wantLevel))
+			assert.Equal(t, tc.method, got[0].Get("method"))
+			assert.Equal(t, tc.wantRoute, got[0].Get("route"))
+			assert.Equal(t, "test", got[0].Get("user_agent"))
+			assert.NotNil(t, got[0].Get("latency"))
+			assert.Equal(t, tc.wantCode, got[0].Get("code"))
+			if tc.body != "" {
+				assert.Equal(t, map[string]any{"name": "kpm"}, got[0].Get("request"))
+				assert.Equal(t, "USR-201", got[0].Get("response").(map[string]any)["code"])
+			}
+		})
+	}
+}
+
+func TestAccessLogBodyLimit(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.DebugLevel, log.FILE)
+	wr := log.NewZapLogger(w)
+	wr.Init(time.Microsecond)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(log.WithCtx(context.Background(), wr))
+		return c.Next()
+	})
+	app.Use(AccessLog(WithBodySampling(1)))
+	app.Get("/big", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"code": "BIG-200", "data": strings.Repeat("a", bodyCaptureSize)})
+	})
+
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/big", nil))
+	require.NoError(t, err)
+	got := obs.TakeAll()
+	require.Len(t, got, 1)
+	assert.Equal(t, "BIG-200", got[0].Get("code"))
+	assert.Greater(t, got[0].Get("bytes"), float64(bodyCaptureSize))
+	assert.Equal(t, map[string]any{}, got[0].Get("response"))
+}
+
+// countReader io.Reader that count how many times it's read.
+type countReader struct {
+	io.Reader
+	reads atomic.Int32
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	c.reads.Add(1)
+	return c.Reader.Read(p)
+}
+
+func TestAccessLogBodyStream(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.DebugLevel, log.FILE)
+	wr := log.NewZapLogger(w)
+	wr.Init(time.Microsecond)
+
+	app := fiber.New()
+	var stream *countReader
+	var readsBeforeSent int32
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(log.WithCtx(context.Background(), wr))
+		err := c.Next()
+		readsBeforeSent = stream.reads.Load()
+		return err
+	})
+	app.Use(AccessLog(WithBodySampling(1)))
+	app.Get("/stream", func(c *fiber.Ctx) error {
+		stream = &countReader{Reader: strings.NewReader(`{"code":"STR-200"}`)}
+		return c.SendStream(stream, 18)
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/stream", nil))
+	require.NoError(t, err)
+	b, _ := io.ReadAll(res.Body)
+	assert.Equal(t, `{"code":"STR-200"}`, string(b))
+	// the stream is not read by the access log
+	assert.Zero(t, readsBeforeSent)
+
+	got := obs.TakeAll()
+	require.Len(t, got, 1)
+	assert.Equal(t, float64(18), got[0].Get("bytes"))
+	assert.Nil(t, got[0].Get("code"))
+	assert.Equal(t, map[string]any{}, got[0].Get("response"))
+}
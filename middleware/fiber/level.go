@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+// LevelHandler return fiber framework handler that read and change the Level
+// of named log.Writer at runtime. Mount it for both GET and PUT method.
+//
+//	lh := log.NewLevelHandler(cns, fl)
+//	app.Get("/log/level", middleware.LevelHandler(lh))
+//	app.Put("/log/level", middleware.LevelHandler(lh))
+func LevelHandler(h *log.LevelHandler) fiber.Handler {
+	return adaptor.HTTPHandler(h)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelHandler(t *testing.T) {
+	cns := log.NewConsoleWriter(log.InfoLevel)
+	app := fiber.New()
+	app.Put("/log/level", LevelHandler(log.NewLevelHandler(cns)))
+
+	req := httptest.NewRequest(http.MethodPut, "/log/level?writer=console", strings.NewReader(`{"level":"debug"}`))
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	b, _ := io.ReadAll(res.Body)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"writer":"console","level":"DEBUG"}`, string(b))
+	assert.Equal(t, log.DebugLevel, cns.Level())
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+	"github.com/mdanialr/api-pkg-go/log"
+)
+
+// NewLogger return fiber log.AllLogger that write logs through given l, so
+// the logs of fiber framework itself and its log package go through the same
+// Writer(s). The level is default to trace, so only the Writer(s) Level
+// decide. SetOutput has no effect on the logs since the Writer(s) decide
+// where the logs are written.
+//
+//	fiberlog.SetLogger(middleware.NewLogger(wr))
+func NewLogger(l log.Logger) fiberlog.AllLogger {
+	return &fiberLogger{l: l, lvl: fiberlog.LevelTrace}
+}
+
+type fiberLogger struct {
+	l   log.Logger
+	lvl fiberlog.Level
+}
+
+func (f *fiberLogger) SetLevel(lvl fiberlog.Level) { f.lvl = lvl }
+func (f *fiberLogger) SetOutput(_ io.Writer)       {}
+
+// WithContext return fiber log.CommonLogger that bind given ctx through
+// log.Logger Ctx.
+func (f *fiberLogger) WithContext(ctx context.Context) fiberlog.CommonLogger {
+	return &fiberLogger{l: f.l.Ctx(ctx), lvl: f.lvl}
+}
+
+func (f *fiberLogger) Trace(v ...interface{}) { f.log(fiberlog.LevelTrace, fmt.Sprint(v...)) }
+func (f *fiberLogger) Debug(v ...interface{}) { f.log(fiberlog.LevelDebug, fmt.Sprint(v...)) }
+func (f *fiberLogger) Info(v ...interface{})  { f.log(fiberlog.LevelInfo, fmt.Sprint(v...)) }
+func (f *fiberLogger) Warn(v ...interface{})  { f.log(fiberlog.LevelWarn, fmt.Sprint(v...)) }
+func (f *fiberLogger) Error(v ...interface{}) { f.log(fiberlog.LevelError, fmt.Sprint(v...)) }
+func (f *fiberLogger) Fatal(v ...interface{}) { f.log(fiberlog.LevelFatal, fmt.Sprint(v...)) }
+func (f *fiberLogger) Panic(v ...interface{}) { f.log(fiberlog.LevelPanic, fmt.Sprint(v...)) }
+
+func (f *fiberLogger) Tracef(s string, v ...interface{}) {
+	f.log(fiberlog.LevelTrace, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Debugf(s string, v ...interface{}) {
+	f.log(fiberlog.LevelDebug, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Infof(s string, v ...interface{}) {
+	f.log(fiberlog.LevelInfo, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Warnf(s string, v ...interface{}) {
+	f.log(fiberlog.LevelWarn, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Errorf(s string, v ...interface{}) {
+	f.log(fiberlog.LevelError, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Fatalf(s string, v ...interface{}) {
+	f.log(fiberlog.LevelFatal, fmt.Sprintf(s, v...))
+}
+func (f *fiberLogger) Panicf(s string, v ...interface{}) {
+	f.log(fiberlog.LevelPanic, fmt.Sprintf(s, v...))
+}
+
+func (f *fiberLogger) Tracew(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelTrace, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Debugw(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelDebug, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Infow(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelInfo, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Warnw(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelWarn, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Errorw(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelError, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Fatalw(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelFatal, msg, pairs(kv)...)
+}
+func (f *fiberLogger) Panicw(msg string, kv ...interface{}) {
+	f.log(fiberlog.LevelPanic, msg, pairs(kv)...)
+}
+
+// log write given msg and pr through the Logger if given lvl is enabled. The
+// panic and fatal level are never disabled the same way as fiber.
+func (f *fiberLogger) log(lvl fiberlog.Level, msg string, pr ...log.Log) {
+	switch lvl {
+	case fiberlog.LevelPanic:
+		f.l.Pnc(msg, pr...)
+		return
+	case fiberlog.LevelFatal:
+		f.l.Ftl(msg, pr...)
+		return
+	}
+	if lvl < f.lvl {
+		return
+	}
+	switch lvl {
+	case fiberlog.LevelTrace:
+		f.l.Trc(msg, pr...)
+	case fiberlog.LevelDebug:
+		f.l.Dbg(msg, pr...)
+	case fiberlog.LevelInfo:
+		f.l.Inf(msg, pr...)
+	case fiberlog.LevelWarn:
+		f.l.Wrn(msg, pr...)
+	default:
+		f.l.Err(msg, pr...)
+	}
+}
+
+// pairs transform given alternating keys and values to log.Log. The key that
+// is not a string or has no value is logged as '!BADKEY' the same way as slog.
+func pairs(kv []interface{}) []log.Log {
+	pr := make([]log.Log, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			pr = append(pr, log.Any("!BADKEY", kv[i]))
+			i--
+			continue
+		}
+		pr = append(pr, log.Any(key, kv[i+1]))
+	}
+	return pr
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fiberlog "github.com/gofiber/fiber/v2/log"
+	"github.com/mdanialr/api-pkg-go/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	w, obs := log.NewObserverWriter(log.TraceLevel, log.FILE)
+	wr := log.NewSlogLogger(w)
+	wr.Init(time.Microsecond)
+
+	fl := NewLogger(wr)
+	fl.Trace("trace ", 1)
+	fl.SetLevel(fiberlog.LevelInfo)
+	fl.Debugf("filtered")
+	fl.Infow("request", "path", "/", "status", 200, 7)
+	fl.WithContext(context.Background()).Warnf("slow %dms", 900)
+	fl.Error("oops")
+	assert.Panics(t, func() { fl.Panicw("boom", "k", "v") })
+
+	got := obs.All()
+	require.Len(t, got, 5)
+	assert.True(t, got[0].EqualMsg("trace 1"))
+	assert.True(t, got[0].EqualLevel(log.TraceLevel))
+	assert.True(t, got[1].EqualMsg("request"))
+	assert.Equal(t, "/", got[1].Get("path"))
+	assert.Equal(t, float64(200), got[1].Get("status"))
+	assert.Equal(t, float64(7), got[1].Get("!BADKEY"))
+	assert.True(t, got[2].EqualMsg("slow 900ms"))
+	assert.True(t, got[3].EqualLevel(log.ErrorLevel))
+	assert.True(t, got[4].EqualLevel(log.PanicLevel))
+	assert.Equal(t, "v", got[4].Get("k"))
+}
+
+func TestPairs(t *testing.T) {
+	assert.Equal(t, []log.Log{log.Any("a", 1), log.Any("!BADKEY", 2), log.Any("b", "c"), log.Any("!BADKEY", "d")}, pairs([]interface{}{"a", 1, 2, "b", "c", "d"}))
+}